@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operasi Mongo yang harus dijalankan setelah perubahan di Postgres commit
+type OutboxOperation string
+
+const (
+	OutboxMongoDelete     OutboxOperation = "mongo_delete"      // Hapus permanen dokumen achievements
+	OutboxMongoSoftDelete OutboxOperation = "mongo_soft_delete" // Set deletedAt (reference di-soft delete)
+	OutboxMongoRestore    OutboxOperation = "mongo_restore"     // Hapus deletedAt (reference di-restore)
+	OutboxMongoScoring    OutboxOperation = "mongo_scoring"     // Simpan poin & scoring (payload OutboxScoringPayload)
+)
+
+// AchievementOutbox: Satu baris tabel achievement_outbox (transactional outbox Postgres -> Mongo).
+// Ditulis di transaksi yang sama dengan perubahan reference, lalu dijalankan worker dengan retry.
+type AchievementOutbox struct {
+	ID                 uuid.UUID       `json:"id"`
+	AchievementID      *uuid.UUID      `json:"achievement_id"` // nil untuk kompensasi create yang gagal
+	MongoAchievementID string          `json:"mongo_achievement_id"`
+	Operation          OutboxOperation `json:"operation"`
+	Payload            json.RawMessage `json:"payload,omitempty"` // Data operasi, kosong untuk operasi tanpa data
+	Attempts           int             `json:"attempts"`
+	LastError          *string         `json:"last_error,omitempty"`
+	NextAttemptAt      time.Time       `json:"next_attempt_at"`
+	CreatedAt          time.Time       `json:"created_at"`
+	ProcessedAt        *time.Time      `json:"processed_at,omitempty"`
+}
+
+// OutboxScoringPayload: Payload OutboxMongoScoring (penyesuaian poin saat verifikasi)
+type OutboxScoringPayload struct {
+	Points  int                `json:"points"`
+	Scoring AchievementScoring `json:"scoring"`
+}
+
+// ReconcileReport: Hasil perbandingan achievement_references vs collection achievements
+type ReconcileReport struct {
+	References     int                    `json:"references"`
+	MongoDocuments int                    `json:"mongo_documents"`
+	Orphans        []string               `json:"orphans"`  // Dokumen Mongo tanpa reference
+	Dangling       []AchievementReference `json:"dangling"` // Reference aktif tanpa dokumen Mongo
+	Fixed          bool                   `json:"fixed"`
+}
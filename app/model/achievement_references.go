@@ -10,13 +10,24 @@ import (
 type AchievementStatus string
 
 const (
-	StatusDraft     AchievementStatus = "draft"
-	StatusSubmitted AchievementStatus = "submitted"
-	StatusVerified  AchievementStatus = "verified"
-	StatusRejected  AchievementStatus = "rejected"
-	StatusDeleted   AchievementStatus = "deleted" // TAMBAHAN 1: Status Baru
+	StatusDraft            AchievementStatus = "draft"
+	StatusSubmitted        AchievementStatus = "submitted"
+	StatusVerified         AchievementStatus = "verified"
+	StatusRejected         AchievementStatus = "rejected"
+	StatusChangesRequested AchievementStatus = "changes_requested" // Dosen meminta perbaikan
+	StatusRevised          AchievementStatus = "revised"           // Sedang diperbaiki mahasiswa setelah rejected / changes_requested
+	StatusDeleted          AchievementStatus = "deleted"           // TAMBAHAN 1: Status Baru
 )
 
+// Valid: Status termasuk enum yang dikenal
+func (s AchievementStatus) Valid() bool {
+	switch s {
+	case StatusDraft, StatusSubmitted, StatusVerified, StatusRejected, StatusChangesRequested, StatusRevised, StatusDeleted:
+		return true
+	}
+	return false
+}
+
 // AchievementReference
 type AchievementReference struct {
 	ID                 uuid.UUID         `json:"id"`
@@ -32,35 +43,60 @@ type AchievementReference struct {
 	DeletedAt          *time.Time        `json:"deleted_at,omitempty"` // TAMBAHAN 2: Field Baru
 }
 
+// AchievementListItem: Reference (Postgres) + ringkasan konten (Mongo) untuk list prestasi
+type AchievementListItem struct {
+	AchievementReference
+	Title           string   `json:"title"`
+	AchievementType string   `json:"achievement_type"`
+	Points          int      `json:"points"`
+	Tags            []string `json:"tags"`
+	AttachmentCount int      `json:"attachment_count"`
+}
+
+// TrashedAchievement: Item di trash admin (reference terhapus + status sebelum dihapus)
+type TrashedAchievement struct {
+	AchievementReference
+	PreviousStatus *AchievementStatus `json:"previous_status"`
+	PurgeAt        *time.Time         `json:"purge_at,omitempty"` // Jadwal hapus permanen oleh retention job
+}
+
 // Request: Create / Submit Awal (Draft)
+// StudentID tidak diambil dari body, melainkan dari user yang login
 type CreateAchievementRequest struct {
-	StudentID       uuid.UUID              `json:"student_id"`
-	AchievementType string                 `json:"achievement_type"`
-	Title           string                 `json:"title"`
-	Description     string                 `json:"description"`
-	Details         map[string]interface{} `json:"details"`
-	Tags            []string               `json:"tags"`
-	Points          int                    `json:"points"`
+	AchievementType string                 `json:"achievement_type" validate:"required,max=50"` // Registry dicek bersama schema details
+	Title           string                 `json:"title" validate:"required,min=3,max=200"`
+	Description     string                 `json:"description" validate:"max=5000"`
+	Details         map[string]interface{} `json:"details"` // Divalidasi terhadap JSON Schema tipe prestasi
+	Tags            []string               `json:"tags" validate:"max=20"`
 }
 
-// Request: Update Prestasi (Hanya bisa saat Draft)
+// Request: Update Prestasi (draft, atau revisi setelah rejected / changes_requested)
 type UpdateAchievementRequest struct {
-	Title       string                 `json:"title"`
-	Description string                 `json:"description"`
-	Details     map[string]interface{} `json:"details"`
-	Tags        []string               `json:"tags"`
-	Points      int                    `json:"points"`
+	AchievementType string                 `json:"achievement_type,omitempty" validate:"omitempty,max=50"` // Opsional: pindah tipe (mis. data lama dengan tipe tidak terdaftar)
+	Title           string                 `json:"title" validate:"required,min=3,max=200"`
+	Description     string                 `json:"description" validate:"max=5000"`
+	Details         map[string]interface{} `json:"details"`
+	Tags            []string               `json:"tags" validate:"max=20"`
 }
 
 // Request: Reject
 type RejectAchievementRequest struct {
-	RejectionNote string `json:"rejection_note"`
+	RejectionNote string `json:"rejection_note" validate:"required,min=5,max=1000"`
 }
 
-// Response: History
+// Request: Minta Perbaikan (Dosen)
+type RequestChangesRequest struct {
+	Note string `json:"note" validate:"required,min=5,max=1000"`
+}
+
+// Response: History (dibangun dari achievement_status_events, urut berdasarkan waktu)
 type AchievementHistoryResponse struct {
-	Status    AchievementStatus `json:"status"`
-	Timestamp time.Time         `json:"timestamp"`
-	Actor     string            `json:"actor"` // "Mahasiswa" atau "Dosen"
-	Note      *string           `json:"note,omitempty"`
-}
\ No newline at end of file
+	FromStatus *AchievementStatus `json:"from_status"`
+	Status     AchievementStatus  `json:"status"`
+	Timestamp  time.Time          `json:"timestamp"`
+	ActorID    *uuid.UUID         `json:"actor_id"`
+	Actor      string             `json:"actor"`      // Nama lengkap user yang melakukan perubahan
+	ActorRole  string             `json:"actor_role"` // admin / lecturer / student / nama role custom
+	Note       *string            `json:"note,omitempty"`
+	RequestID  string             `json:"request_id,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementSearchHit: Satu hasil /achievements/search
+type AchievementSearchHit struct {
+	ID              uuid.UUID         `json:"id"` // ID achievement_references
+	StudentID       uuid.UUID         `json:"student_id"`
+	Status          AchievementStatus `json:"status"`
+	Title           string            `json:"title"`
+	AchievementType string            `json:"achievement_type"`
+	Tags            []string          `json:"tags"`
+	Points          int               `json:"points"`
+	Score           float64           `json:"score"`
+	Highlights      map[string]string `json:"highlights"` // field -> potongan teks (HTML-escaped) dengan <em>
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// FacetCount: Jumlah hasil per nilai facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// AchievementSearchFacets: Facet dihitung dari seluruh hasil (bukan hanya halaman ini)
+type AchievementSearchFacets struct {
+	AchievementType []FacetCount `json:"achievement_type"`
+	Tag             []FacetCount `json:"tag"`
+	Status          []FacetCount `json:"status"`
+	Year            []FacetCount `json:"year"`
+}
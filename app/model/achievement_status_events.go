@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementStatusEvent: Satu baris event log perubahan status (tabel achievement_status_events)
+type AchievementStatusEvent struct {
+	ID            uuid.UUID          `json:"id"`
+	AchievementID uuid.UUID          `json:"achievement_id"`
+	OldStatus     *AchievementStatus `json:"old_status"` // nil untuk event pembuatan draft
+	NewStatus     AchievementStatus  `json:"new_status"`
+	ActorUserID   *uuid.UUID         `json:"actor_user_id"`
+	ActorRole     string             `json:"actor_role"`
+	Note          *string            `json:"note,omitempty"`
+	RequestID     string             `json:"request_id,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
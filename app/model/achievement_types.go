@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementType: Registry tipe prestasi (tabel achievement_types).
+// DetailsSchema adalah JSON Schema untuk field details, dipakai untuk validasi dan render form di frontend.
+type AchievementType struct {
+	ID            uuid.UUID       `json:"id"`
+	Code          string          `json:"code"` // Disimpan di Mongo sebagai achievementType
+	Name          string          `json:"name" validate:"omitempty,max=100"`
+	Description   *string         `json:"description"`
+	DetailsSchema json.RawMessage `json:"details_schema" swaggertype:"object"`
+	IsActive      bool            `json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// Request: Tambah tipe prestasi (Admin)
+type CreateAchievementTypeRequest struct {
+	Code          string          `json:"code" validate:"required"` // Format dicek di service (huruf kecil, angka, _)
+	Name          string          `json:"name" validate:"required,max=100"`
+	Description   *string         `json:"description"`
+	DetailsSchema json.RawMessage `json:"details_schema" swaggertype:"object"`
+}
+
+// Request: Update tipe prestasi (code tidak bisa diubah karena sudah tersimpan di dokumen prestasi)
+type UpdateAchievementTypeRequest struct {
+	Name          string          `json:"name"`
+	Description   *string         `json:"description"`
+	DetailsSchema json.RawMessage `json:"details_schema" swaggertype:"object"`
+	IsActive      *bool           `json:"is_active"`
+}
@@ -22,14 +22,109 @@ type Achievement struct {
 	Attachments []Attachment `bson:"attachments" json:"attachments"`
 
 	Tags      []string  `bson:"tags" json:"tags"`
-	Points    int       `bson:"points" json:"points"`
-	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
-	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
+	Points    int       `bson:"points" json:"points"` // Poin efektif: hasil aturan skoring, atau penyesuaian dosen jika ada
+
+	Scoring *AchievementScoring `bson:"scoring,omitempty" json:"scoring,omitempty"`
+
+	CreatedAt time.Time  `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updated_at"`
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"` // Soft delete (ikut reference)
+}
+
+// AchievementScoring: Asal perhitungan poin (dihitung server dari scoring_rules)
+type AchievementScoring struct {
+	RuleID         *uuid.UUID        `bson:"ruleId,omitempty" json:"rule_id,omitempty"` // nil = tidak ada aturan yang cocok (0 poin)
+	ComputedPoints int               `bson:"computedPoints" json:"computed_points"`
+	Adjustment     *PointsAdjustment `bson:"adjustment,omitempty" json:"adjustment,omitempty"`
+	CalculatedAt   time.Time         `bson:"calculatedAt" json:"calculated_at"`
+}
+
+// PointsAdjustment: Penyesuaian poin oleh dosen saat verifikasi (menggantikan hasil aturan)
+type PointsAdjustment struct {
+	Points        int       `bson:"points" json:"points"`
+	Justification string    `bson:"justification" json:"justification"`
+	AdjustedBy    uuid.UUID `bson:"adjustedBy" json:"adjusted_by"`
+	AdjustedAt    time.Time `bson:"adjustedAt" json:"adjusted_at"`
 }
 
 type Attachment struct {
+	ID         string    `bson:"id,omitempty" json:"id,omitempty"` // Kosong untuk lampiran lama (sebelum storage backend)
 	FileName   string    `bson:"fileName" json:"file_name"`
-	FileURL    string    `bson:"fileUrl" json:"file_url"`
-	FileType   string    `bson:"fileType" json:"file_type"`
+	FileURL    string    `bson:"fileUrl" json:"file_url"` // Endpoint unduh terautentikasi
+	FileType   string    `bson:"fileType" json:"file_type"` // Hasil deteksi magic bytes, bukan header client
+	Size       int64     `bson:"size,omitempty" json:"size,omitempty"`
+	SHA256     string    `bson:"sha256,omitempty" json:"sha256,omitempty"` // Checksum isi file (hex)
+	StorageKey string    `bson:"storageKey,omitempty" json:"-"` // Key content-addressed di storage
 	UploadedAt time.Time `bson:"uploadedAt" json:"uploaded_at"`
-}
\ No newline at end of file
+
+	ScanStatus    AttachmentScanStatus `bson:"scanStatus,omitempty" json:"scan_status,omitempty"`       // Kosong = lampiran lama, belum dipindai
+	ScanSignature string               `bson:"scanSignature,omitempty" json:"scan_signature,omitempty"` // Nama malware jika infected
+	ScannedAt     *time.Time           `bson:"scannedAt,omitempty" json:"scanned_at,omitempty"`
+	ScanAttempts  int                  `bson:"scanAttempts,omitempty" json:"-"` // Percobaan pindai ulang yang gagal membuka file
+
+	PreviewKey    string                  `bson:"previewKey,omitempty" json:"-"`    // Thumbnail JPEG di storage (lihat storage.PreviewKey)
+	PreviewStatus AttachmentPreviewStatus `bson:"previewStatus,omitempty" json:"-"` // Kosong = belum diproses worker preview
+	PreviewURL    string                  `bson:"-" json:"preview_url,omitempty"`   // Signed URL, hanya diisi di detail prestasi
+}
+
+// Status pembuatan preview lampiran
+type AttachmentPreviewStatus string
+
+const (
+	PreviewStatusReady       AttachmentPreviewStatus = "ready"
+	PreviewStatusUnsupported AttachmentPreviewStatus = "unsupported" // Renderer tidak tersedia (mis. pdftoppm belum terpasang)
+	PreviewStatusFailed      AttachmentPreviewStatus = "failed"      // File tidak bisa dibaca / dirender
+)
+
+// Status pemindaian malware lampiran
+type AttachmentScanStatus string
+
+const (
+	ScanStatusPending  AttachmentScanStatus = "pending" // Scanner tidak tersedia saat upload, dipindai ulang oleh worker
+	ScanStatusClean    AttachmentScanStatus = "clean"
+	ScanStatusInfected AttachmentScanStatus = "infected" // File dipindah ke karantina dan tidak bisa diunduh
+	ScanStatusSkipped  AttachmentScanStatus = "skipped"  // Pemindaian dinonaktifkan (SCANNER_DRIVER=none)
+	ScanStatusFailed   AttachmentScanStatus = "failed"   // File tidak ada / tidak bisa dibaca dari storage, lampiran harus diganti
+)
+
+// AttachmentURLResponse: Signed URL sementara untuk mengunduh lampiran tanpa header Authorization
+type AttachmentURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Aksi yang menghasilkan versi baru konten prestasi
+type AchievementVersionAction string
+
+const (
+	VersionActionBaseline          AchievementVersionAction = "baseline" // Konten lama sebelum versioning ada
+	VersionActionCreate            AchievementVersionAction = "create"
+	VersionActionUpdate            AchievementVersionAction = "update"
+	VersionActionAttachment        AchievementVersionAction = "attachment"
+	VersionActionAttachmentDelete  AchievementVersionAction = "attachment_delete"
+	VersionActionAttachmentReplace AchievementVersionAction = "attachment_replace"
+)
+
+// AchievementVersion: Snapshot konten Mongo setelah setiap perubahan (collection achievement_versions)
+type AchievementVersion struct {
+	ID            primitive.ObjectID       `bson:"_id,omitempty" json:"id"`
+	AchievementID uuid.UUID                `bson:"achievementId" json:"achievement_id"` // ID achievement_references
+	Version       int                      `bson:"version" json:"version"`
+	Action        AchievementVersionAction `bson:"action" json:"action"`
+	Content       Achievement              `bson:"content" json:"content"`
+	AuthorID      *uuid.UUID               `bson:"authorId,omitempty" json:"author_id,omitempty"`
+	AuthorRole    string                   `bson:"authorRole" json:"author_role"`
+	CreatedAt     time.Time                `bson:"createdAt" json:"created_at"`
+}
+
+// AchievementRevision: Snapshot konten Mongo setiap kali prestasi disubmit (collection achievement_revisions).
+// Dipakai dosen untuk melihat perubahan sejak penolakan / permintaan perbaikan.
+type AchievementRevision struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID uuid.UUID          `bson:"achievementId" json:"achievement_id"` // ID achievement_references
+	Revision      int                `bson:"revision" json:"revision"`
+	Version       int                `bson:"version,omitempty" json:"version,omitempty"` // Versi konten yang disubmit
+	Content       Achievement        `bson:"content" json:"content"`
+	SubmittedBy   uuid.UUID          `bson:"submittedBy" json:"submitted_by"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "github.com/google/uuid"
+
+// ActorKind: Jenis aktor hasil resolusi users.role_id
+type ActorKind string
+
+const (
+	ActorAdmin    ActorKind = "admin"
+	ActorLecturer ActorKind = "lecturer"
+	ActorStudent  ActorKind = "student"
+	ActorCustom   ActorKind = "custom" // Role buatan admin, tanpa akses data implisit
+)
+
+// Actor: Identitas user yang sedang login (disimpan di Locals("actor") oleh AuthProtected)
+type Actor struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	RoleID     uuid.UUID  `json:"role_id"`
+	RoleName   string     `json:"role_name"`
+	Kind       ActorKind  `json:"kind"`
+	StudentID  *uuid.UUID `json:"student_id,omitempty"`  // PK tabel students (jika Kind == student)
+	LecturerID *uuid.UUID `json:"lecturer_id,omitempty"` // PK tabel lecturers (jika Kind == lecturer)
+}
+
+func (a *Actor) IsAdmin() bool {
+	return a != nil && a.Kind == ActorAdmin
+}
+
+// IsStudentOwner: true jika actor adalah mahasiswa dengan PK students tersebut
+func (a *Actor) IsStudentOwner(studentID uuid.UUID) bool {
+	return a != nil && a.Kind == ActorStudent && a.StudentID != nil && *a.StudentID == studentID
+}
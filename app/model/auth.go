@@ -4,13 +4,13 @@ import "github.com/google/uuid"
 
 // Request untuk Login
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 // Request untuk Refresh Token
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" example:"a3f5d9c2-8e34-4f90-9d21-1cfa9f4b8e71"`
+	RefreshToken string `json:"refresh_token" example:"a3f5d9c2-8e34-4f90-9d21-1cfa9f4b8e71" validate:"required"`
 }
 
 
@@ -34,6 +34,6 @@ type LoginResponseData struct {
 
 // AuthResponse: Wrapper utama
 type AuthResponse struct {
-	Status string      `json:"status"` // "success"
-	Data   interface{} `json:"data"`   // Bisa LoginResponseData atau User Profile
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data"` // Bisa LoginResponseData atau User Profile
 }
\ No newline at end of file
@@ -18,13 +18,13 @@ type Lecturer struct {
 // --- STRUCT REQUEST UNTUK CREATE ---
 
 type CreateLecturerRequest struct {
-	UserID     uuid.UUID `json:"user_id"`
-	LecturerID string    `json:"lecturer_id"`
-	Department string    `json:"department"`
+	UserID     uuid.UUID `json:"user_id" validate:"required,exists=users"`
+	LecturerID string    `json:"lecturer_id" validate:"required,max=20"`
+	Department string    `json:"department" validate:"max=100"`
 }
 
 // Struct Update 
 type UpdateLecturerRequest struct {
-	LecturerID string `json:"lecturer_id"`
-	Department string `json:"department"`
+	LecturerID string `json:"lecturer_id" validate:"max=20"`
+	Department string `json:"department" validate:"max=100"`
 }
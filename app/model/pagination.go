@@ -0,0 +1,40 @@
+package model
+
+// SortField: Satu field di parameter ?sort=-created_at,full_name ("-" = descending)
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListParams: Parameter list yang sudah di-parse dari query string (lihat service/list_query.go).
+// Filters berisi query param lain (mis. status, program_study, created_from); repository hanya
+// memakai filter yang terdaftar di spec-nya.
+type ListParams struct {
+	Page    int
+	Limit   int
+	After   string // Cursor dari next_cursor; jika diisi, Page diabaikan
+	Sort    []SortField
+	Filters map[string]string
+}
+
+// ListResult: Hasil query list dari repository
+type ListResult[T any] struct {
+	Items      []T
+	Total      int
+	NextCursor string
+}
+
+// ListMeta: Bagian "meta" di response list
+type ListMeta struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"` // Kosong jika memakai cursor
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListLinks: Bagian "links" di response list
+type ListLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
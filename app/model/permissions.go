@@ -15,16 +15,31 @@ type Permission struct {
 
 // Struct request Create 
 type CreatePermissionRequest struct {
-	Name        string  `json:"name"`
-	Resource    string  `json:"resource"`    
-	Action      string  `json:"action"`      
+	Name        string  `json:"name" validate:"required,max=100"`
+	Resource    string  `json:"resource" validate:"required,max=50"`
+	Action      string  `json:"action" validate:"required,max=50"`
 	Description *string `json:"description"`
 }
 
 // Struct request Update 
 type UpdatePermissionRequest struct {
-	Name        string  `json:"name"`
-	Resource    string  `json:"resource"`    
-	Action      string  `json:"action"`      
+	Name        string  `json:"name" validate:"required,max=100"`
+	Resource    string  `json:"resource" validate:"required,max=50"`
+	Action      string  `json:"action" validate:"required,max=50"`
 	Description *string `json:"description"`
-}
\ No newline at end of file
+}
+// Permission khusus pengelolaan RBAC (lihat route roles, permissions, role-permissions)
+const (
+	PermissionRoleManage       = "role:manage"
+	PermissionPermissionManage = "permission:manage"
+	PermissionUserAssignRole   = "user:assign_role"
+)
+
+// Permission trash prestasi (list / restore / purge), default hanya Admin
+const PermissionAchievementTrash = "achievement:trash"
+
+// Permission pengelolaan registry tipe prestasi (schema details), default hanya Admin
+const PermissionAchievementTypeManage = "achievement_type:manage"
+
+// Permission pengelolaan aturan poin prestasi, default hanya Admin
+const PermissionScoringRuleManage = "scoring_rule:manage"
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken: Refresh token yang disimpan di Postgres (hanya hash-nya)
+// Satu "family" dibuat setiap kali login, dan setiap rotasi tetap berada di family yang sama.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FamilyID  uuid.UUID  `json:"family_id"`
+	TokenHash string     `json:"-"` // Hidden (SHA-256 dari token asli)
+	UserAgent string     `json:"user_agent"`
+	IPAddress string     `json:"ip_address"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`    // Terisi saat token sudah dirotasi
+	RevokedAt *time.Time `json:"revoked_at"` // Terisi saat family dicabut
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenResponseData: Isi field 'data' pada response /auth/refresh
+type RefreshTokenResponseData struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
@@ -17,7 +17,7 @@ type StudentReportResponse struct {
 	FullName          string                  `json:"full_name"`
 	ProgramStudy      string                  `json:"program_study"`
 	TotalAchievements int                     `json:"total_achievements"`
-	TotalPoints       int                     `json:"total_points"`       // Hitung poin dari Mongo
+	TotalPoints       int                     `json:"total_points"`       // Jumlah poin prestasi berstatus verified
 	Achievements      []SimpleAchievementView `json:"achievements_list"`
 }
 
@@ -9,6 +9,6 @@ type RolePermission struct {
 
 // Struct untuk assign permission ke role
 type AssignPermissionRequest struct {
-	RoleID       uuid.UUID `json:"role_id"`
-	PermissionID uuid.UUID `json:"permission_id"`
+	RoleID       uuid.UUID `json:"role_id" validate:"required,exists=roles"`
+	PermissionID uuid.UUID `json:"permission_id" validate:"required,exists=permissions"`
 }
\ No newline at end of file
@@ -15,12 +15,12 @@ type Role struct {
 
 // Struct untuk request body saat Create
 type CreateRoleRequest struct {
-	Name        string  `json:"name"`
+	Name        string  `json:"name" validate:"required,max=50"`
 	Description *string `json:"description"`
 }
 
 // Struct untuk request body saat Update
 type UpdateRoleRequest struct {
-	Name        string  `json:"name"`
+	Name        string  `json:"name" validate:"required,max=50"`
 	Description *string `json:"description"`
 }
\ No newline at end of file
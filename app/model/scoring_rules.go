@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Nilai participation di details prestasi (default individual jika tidak diisi)
+const (
+	ParticipationIndividual = "individual"
+	ParticipationTeam       = "team"
+)
+
+// ScoringRule: Aturan poin prestasi (tabel scoring_rules). Kondisi yang nil berarti "apa saja".
+// Jika beberapa aturan cocok, dipilih priority tertinggi lalu aturan yang paling spesifik.
+type ScoringRule struct {
+	ID              uuid.UUID `json:"id"`
+	AchievementType string    `json:"achievement_type"` // Code di achievement_types
+	Level           *string   `json:"level"`            // details.level (mis. national)
+	RankMin         *int      `json:"rank_min"`         // details.rank >= rank_min
+	RankMax         *int      `json:"rank_max"`         // details.rank <= rank_max
+	Participation   *string   `json:"participation"`    // details.participation: individual / team
+	Points          int       `json:"points"`
+	Priority        int       `json:"priority"`
+	Description     *string   `json:"description"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Request: Tambah / ubah aturan poin (Admin)
+type ScoringRuleRequest struct {
+	AchievementType string  `json:"achievement_type" validate:"required,exists=achievement_types"`
+	Level           *string `json:"level" validate:"omitempty,max=50"`
+	RankMin         *int    `json:"rank_min" validate:"omitempty,min=1"`
+	RankMax         *int    `json:"rank_max" validate:"omitempty,min=1"` // Harus >= rank_min (dicek di service)
+	Participation   *string `json:"participation" validate:"omitempty,oneof=individual team"`
+	Points          int     `json:"points" validate:"min=0"`
+	Priority        int     `json:"priority"`
+	Description     *string `json:"description"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+// Request: Verifikasi prestasi, opsional dengan penyesuaian poin
+type VerifyAchievementRequest struct {
+	Points        *int   `json:"points" validate:"omitempty,min=0"` // Kosong = pakai poin hasil aturan
+	Justification string `json:"justification" validate:"max=1000"` // Wajib jika points diisi (dicek di service)
+}
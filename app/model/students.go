@@ -18,22 +18,22 @@ type Student struct {
 
 // Struct Request Create
 type CreateStudentRequest struct {
-	UserID       uuid.UUID  `json:"user_id"`
-	StudentID    string     `json:"student_id"`
-	ProgramStudy string     `json:"program_study"`
-	AcademicYear string     `json:"academic_year"`
-	AdvisorID    *uuid.UUID `json:"advisor_id"`
+	UserID       uuid.UUID  `json:"user_id" validate:"required,exists=users"`
+	StudentID    string     `json:"student_id" validate:"required,max=20"`
+	ProgramStudy string     `json:"program_study" validate:"max=100"`
+	AcademicYear string     `json:"academic_year" validate:"max=10"`
+	AdvisorID    *uuid.UUID `json:"advisor_id" validate:"omitempty,exists=lecturers"`
 }
 
 // Struct Request Update
 type UpdateStudentRequest struct {
-	StudentID    string     `json:"student_id"`
-	ProgramStudy string     `json:"program_study"`
-	AcademicYear string     `json:"academic_year"`
-	AdvisorID    *uuid.UUID `json:"advisor_id"`
+	StudentID    string     `json:"student_id" validate:"omitempty,max=20"`
+	ProgramStudy string     `json:"program_study" validate:"omitempty,max=100"`
+	AcademicYear string     `json:"academic_year" validate:"omitempty,max=10"`
+	AdvisorID    *uuid.UUID `json:"advisor_id" validate:"omitempty,exists=lecturers"`
 }
 
 // Request khusus update advisor (Endpoint: PUT /students/:id/advisor)
 type UpdateAdvisorRequest struct {
-	AdvisorID *uuid.UUID `json:"advisor_id"`
+	AdvisorID *uuid.UUID `json:"advisor_id" validate:"omitempty,exists=lecturers"` // null = lepas dosen wali
 }
\ No newline at end of file
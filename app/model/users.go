@@ -21,23 +21,23 @@ type User struct {
 
 // Request Create User
 type CreateUserRequest struct {
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Password string    `json:"password"`
-	FullName string    `json:"full_name"`
-	RoleID   uuid.UUID `json:"role_id"`
+	Username string    `json:"username" validate:"required,min=3,max=50"`
+	Email    string    `json:"email" validate:"required,email,max=100"`
+	Password string    `json:"password" validate:"required,notrim,min=8,maxbytes=72"` // bcrypt maksimal 72 byte
+	FullName string    `json:"full_name" validate:"required,max=100"`
+	RoleID   uuid.UUID `json:"role_id" validate:"required,exists=roles"`
 }
 
 // Request Update User
 type UpdateUserRequest struct {
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	FullName string    `json:"full_name"`
-	RoleID   uuid.UUID `json:"role_id"`
+	Username string    `json:"username" validate:"omitempty,min=3,max=50"`
+	Email    string    `json:"email" validate:"omitempty,email,max=100"`
+	FullName string    `json:"full_name" validate:"omitempty,max=100"`
+	RoleID   uuid.UUID `json:"role_id" validate:"omitempty,exists=roles"`
 	IsActive *bool     `json:"is_active"`
 }
 
 // Request Update Role Only
 type UpdateUserRoleRequest struct {
-	RoleID uuid.UUID `json:"role_id"`
+	RoleID uuid.UUID `json:"role_id" validate:"required,exists=roles"`
 }
\ No newline at end of file
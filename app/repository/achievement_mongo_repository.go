@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"project-uas/app/model"
 	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const collectionName = "achievements"
@@ -58,14 +62,415 @@ func DeleteAchievementMongo(db *mongo.Database, hexID string) error {
 	return err
 }
 
-// Add Attachment
-func AddAttachmentMongo(db *mongo.Database, hexID string, attachment model.Attachment) error {
+// Soft Delete (deletedAt mengikuti reference di Postgres)
+func SoftDeleteAchievementMongo(db *mongo.Database, hexID string, at time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	objID, err := primitive.ObjectIDFromHex(hexID)
 	if err != nil { return err }
-	
+	_, err = db.Collection(collectionName).UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"deletedAt": at}})
+	return err
+}
+
+// Restore (hapus deletedAt)
+func RestoreAchievementMongo(db *mongo.Database, hexID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+	_, err = db.Collection(collectionName).UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$unset": bson.M{"deletedAt": ""}})
+	return err
+}
+
+// ErrAttachmentQuotaExceeded: Update lampiran ditolak filter kuota (jumlah / total ukuran / file sama),
+// atau dokumen / lampiran sudah berubah sejak dibaca
+var ErrAttachmentQuotaExceeded = errors.New("kuota lampiran terlampaui")
+
+// attachmentTotalSizeWithin: Filter $expr total ukuran lampiran saat ini - removed + added <= maxTotalSize
+func attachmentTotalSizeWithin(removed, added, maxTotalSize int64) bson.M {
+	return bson.M{"$lte": bson.A{
+		bson.M{"$add": bson.A{bson.M{"$sum": "$attachments.size"}, added - removed}},
+		maxTotalSize,
+	}}
+}
+
+// AddAttachmentMongo: Tambah lampiran hanya jika kuota masih cukup, dicek atomik di filter update
+// (jumlah < maxCount, total ukuran + attachment.Size <= maxTotalSize, belum ada file dengan sha256 yang sama).
+// ErrAttachmentQuotaExceeded jika filter tidak cocok.
+func AddAttachmentMongo(db *mongo.Database, hexID string, attachment model.Attachment, maxCount int, maxTotalSize int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	filter := bson.M{
+		"_id": objID,
+		fmt.Sprintf("attachments.%d", maxCount-1): bson.M{"$exists": false},
+		"$expr": attachmentTotalSizeWithin(0, attachment.Size, maxTotalSize),
+	}
+	if attachment.SHA256 != "" {
+		filter["attachments.sha256"] = bson.M{"$ne": attachment.SHA256}
+	}
 	update := bson.M{"$push": bson.M{"attachments": attachment}, "$set": bson.M{"updatedAt": time.Now()}}
-	_, err = db.Collection(collectionName).UpdateOne(ctx, bson.M{"_id": objID}, update)
+	res, err := db.Collection(collectionName).UpdateOne(ctx, filter, update)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return ErrAttachmentQuotaExceeded }
+	return nil
+}
+
+// attachmentMatch: Lampiran dicari dengan id; lampiran lama (tanpa id) dengan fileUrl
+func attachmentMatch(a model.Attachment) bson.M {
+	if a.ID != "" {
+		return bson.M{"id": a.ID}
+	}
+	return bson.M{"id": bson.M{"$exists": false}, "fileUrl": a.FileURL}
+}
+
+// attachmentContentMatch: Lampiran yang sama dan isinya belum diganti (masih memakai storageKey tersebut)
+func attachmentContentMatch(a model.Attachment, storageKey string) bson.M {
+	match := attachmentMatch(a)
+	if storageKey != "" {
+		match["storageKey"] = storageKey
+	} else {
+		match["storageKey"] = bson.M{"$exists": false}
+	}
+	return match
+}
+
+// RemoveAttachmentMongo: Hapus satu lampiran dari array; mongo.ErrNoDocuments jika lampiran sudah tidak ada
+func RemoveAttachmentMongo(db *mongo.Database, hexID string, attachment model.Attachment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	filter := bson.M{"_id": objID, "attachments": bson.M{"$elemMatch": attachmentMatch(attachment)}}
+	update := bson.M{"$pull": bson.M{"attachments": attachmentMatch(attachment)}, "$set": bson.M{"updatedAt": time.Now()}}
+	res, err := db.Collection(collectionName).UpdateOne(ctx, filter, update)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+// ReplaceAttachmentMongo: Ganti lampiran lama dengan data baru di posisi yang sama. Lampiran lama harus masih
+// memakai isi yang sama (storageKey) dan total ukuran setelah diganti <= maxTotalSize, dicek atomik di filter update.
+// ErrAttachmentQuotaExceeded jika filter tidak cocok.
+func ReplaceAttachmentMongo(db *mongo.Database, hexID string, old, replacement model.Attachment, maxTotalSize int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	filter := bson.M{
+		"_id":         objID,
+		"attachments": bson.M{"$elemMatch": attachmentContentMatch(old, old.StorageKey)},
+		"$expr":       attachmentTotalSizeWithin(old.Size, replacement.Size, maxTotalSize),
+	}
+	update := bson.M{"$set": bson.M{"attachments.$": replacement, "updatedAt": time.Now()}}
+	res, err := db.Collection(collectionName).UpdateOne(ctx, filter, update)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return ErrAttachmentQuotaExceeded }
+	return nil
+}
+
+// CountAttachmentStorageKeyRefs: Jumlah dokumen prestasi (termasuk yang di trash) yang masih memakai key storage.
+// exceptHexID (opsional) tidak ikut dihitung, mis. dokumen yang sedang di-purge.
+func CountAttachmentStorageKeyRefs(db *mongo.Database, storageKey, exceptHexID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{"attachments.storageKey": storageKey}
+	if exceptHexID != "" {
+		objID, err := primitive.ObjectIDFromHex(exceptHexID)
+		if err != nil { return 0, err }
+		filter["_id"] = bson.M{"$ne": objID}
+	}
+	return db.Collection(collectionName).CountDocuments(ctx, filter)
+}
+
+// ListAchievementsPendingScan: Dokumen aktif yang punya lampiran berstatus pending / belum pernah dipindai
+// (hanya _id & attachments), urut _id setelah afterHexID ("" = dari awal)
+func ListAchievementsPendingScan(db *mongo.Database, afterHexID string, limit int) ([]model.Achievement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"deletedAt": bson.M{"$exists": false},
+		"attachments": bson.M{"$elemMatch": bson.M{"$or": bson.A{
+			bson.M{"scanStatus": model.ScanStatusPending},
+			bson.M{"scanStatus": bson.M{"$exists": false}},
+		}}},
+	}
+	if err := afterObjectID(filter, afterHexID); err != nil { return nil, err }
+	opts := options.Find().SetProjection(bson.M{"attachments": 1}).SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, opts)
+	if err != nil { return nil, err }
+	var docs []model.Achievement
+	if err := cursor.All(ctx, &docs); err != nil { return nil, err }
+	return docs, nil
+}
+
+// afterObjectID menambahkan batas _id > afterHexID untuk paging worker
+func afterObjectID(filter bson.M, afterHexID string) error {
+	if afterHexID == "" {
+		return nil
+	}
+	objID, err := primitive.ObjectIDFromHex(afterHexID)
+	if err != nil { return err }
+	filter["_id"] = bson.M{"$gt": objID}
+	return nil
+}
+
+// UpdateAttachmentScanMongo: Simpan hasil pindai (status, signature, waktu, key karantina) ke lampiran.
+// Hanya berlaku jika lampiran masih memakai storageKey yang dipindai (tidak diganti di tengah pemindaian).
+func UpdateAttachmentScanMongo(db *mongo.Database, hexID string, scanned model.Attachment, scannedKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	filter := bson.M{"_id": objID, "attachments": bson.M{"$elemMatch": attachmentContentMatch(scanned, scannedKey)}}
+	set := bson.M{
+		"attachments.$.scanStatus":    scanned.ScanStatus,
+		"attachments.$.scanSignature": scanned.ScanSignature,
+		"attachments.$.scannedAt":     scanned.ScannedAt,
+		"attachments.$.scanAttempts":  scanned.ScanAttempts,
+	}
+	if scanned.StorageKey != "" {
+		set["attachments.$.storageKey"] = scanned.StorageKey
+	}
+	res, err := db.Collection(collectionName).UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+// ListAchievementsPendingPreview: Dokumen aktif yang punya lampiran sudah lolos pindai, bertipe fileTypes,
+// dan belum diproses worker preview (hanya _id & attachments), urut _id setelah afterHexID ("" = dari awal)
+func ListAchievementsPendingPreview(db *mongo.Database, fileTypes []string, afterHexID string, limit int) ([]model.Achievement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"deletedAt": bson.M{"$exists": false},
+		"attachments": bson.M{"$elemMatch": bson.M{
+			"scanStatus":    bson.M{"$in": bson.A{model.ScanStatusClean, model.ScanStatusSkipped}},
+			"fileType":      bson.M{"$in": fileTypes},
+			"previewStatus": bson.M{"$exists": false},
+		}},
+	}
+	if err := afterObjectID(filter, afterHexID); err != nil { return nil, err }
+	opts := options.Find().SetProjection(bson.M{"attachments": 1}).SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, opts)
+	if err != nil { return nil, err }
+	var docs []model.Achievement
+	if err := cursor.All(ctx, &docs); err != nil { return nil, err }
+	return docs, nil
+}
+
+// UpdateAttachmentPreviewMongo: Simpan hasil worker preview (status & key) jika isi lampiran belum diganti
+func UpdateAttachmentPreviewMongo(db *mongo.Database, hexID string, a model.Attachment, sourceKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	filter := bson.M{"_id": objID, "attachments": bson.M{"$elemMatch": attachmentContentMatch(a, sourceKey)}}
+	set := bson.M{"attachments.$.previewStatus": a.PreviewStatus}
+	if a.PreviewKey != "" {
+		set["attachments.$.previewKey"] = a.PreviewKey
+	}
+	res, err := db.Collection(collectionName).UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+// ListAchievementMongoIDs: Semua _id dokumen achievements beserta createdAt (untuk rekonsiliasi)
+func ListAchievementMongoIDs(db *mongo.Database) (map[string]time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "createdAt": 1})
+	cursor, err := db.Collection(collectionName).Find(ctx, bson.M{}, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	ids := make(map[string]time.Time)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			CreatedAt time.Time          `bson:"createdAt"`
+		}
+		if err := cursor.Decode(&doc); err != nil { return nil, err }
+		ids[doc.ID.Hex()] = doc.CreatedAt
+	}
+	return ids, cursor.Err()
+}
+
+// GetAchievementsMongoByIDs mengambil banyak dokumen sekaligus dengan satu query $in.
+// Hasil di-key dengan hex ID; ID yang tidak valid / tidak ditemukan tidak ada di map.
+func GetAchievementsMongoByIDs(db *mongo.Database, hexIDs []string) (map[string]model.Achievement, error) {
+	result := make(map[string]model.Achievement, len(hexIDs))
+	objIDs := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		if objID, err := primitive.ObjectIDFromHex(hexID); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := db.Collection(collectionName).Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc model.Achievement
+		if err := cursor.Decode(&doc); err != nil { return nil, err }
+		result[doc.ID.Hex()] = doc
+	}
+	return result, cursor.Err()
+}
+
+// AchievementScoringDoc: Field yang dibutuhkan untuk menghitung ulang poin
+type AchievementScoringDoc struct {
+	ID              primitive.ObjectID        `bson:"_id"`
+	AchievementType string                    `bson:"achievementType"`
+	Details         map[string]interface{}    `bson:"details"`
+	Points          int                       `bson:"points"`
+	Scoring         *model.AchievementScoring `bson:"scoring"`
+}
+
+// ListAchievementsForScoring: Dokumen satu tipe prestasi (kosong = semua tipe) untuk hitung ulang poin
+func ListAchievementsForScoring(db *mongo.Database, achievementType string) ([]AchievementScoringDoc, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if achievementType != "" {
+		filter["achievementType"] = achievementType
+	}
+	opts := options.Find().SetProjection(bson.M{"achievementType": 1, "details": 1, "points": 1, "scoring": 1})
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	var docs []AchievementScoringDoc
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+// UpdateAchievementScoring menyimpan poin efektif + asal perhitungannya.
+// updatedAt tidak diubah karena konten prestasi tidak berubah.
+func UpdateAchievementScoring(db *mongo.Database, hexID string, points int, scoring model.AchievementScoring) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil { return err }
+
+	_, err = db.Collection(collectionName).UpdateOne(ctx, bson.M{"_id": objID},
+		bson.M{"$set": bson.M{"points": points, "scoring": scoring}})
 	return err
-}
\ No newline at end of file
+}
+
+// AchievementContentFilter: Filter konten Mongo untuk list lintas store
+type AchievementContentFilter struct {
+	AchievementType string
+	Tag             string
+	StudentID       *uuid.UUID // Mempersempit pencarian untuk scope mahasiswa
+}
+
+// FindAchievementMongoIDs: Hex ID dokumen (belum dihapus) yang cocok dengan filter konten
+func FindAchievementMongoIDs(db *mongo.Database, f AchievementContentFilter) ([]string, error) {
+	filter := bson.M{"deletedAt": bson.M{"$exists": false}}
+	if f.AchievementType != "" {
+		filter["achievementType"] = f.AchievementType
+	}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	if f.StudentID != nil {
+		filter["studentId"] = *f.StudentID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	ids := []string{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil { return nil, err }
+		ids = append(ids, doc.ID.Hex())
+	}
+	return ids, cursor.Err()
+}
+
+// AchievementSearchDoc: Dokumen hasil $text beserta skor relevansi
+type AchievementSearchDoc struct {
+	model.Achievement `bson:",inline"`
+	Score             float64 `bson:"score"`
+}
+
+// AchievementSearchFilter: Filter pencarian di sisi Mongo (scope & facet yang ada di Mongo)
+type AchievementSearchFilter struct {
+	Query           string
+	StudentIDs      []uuid.UUID // nil = semua mahasiswa (admin)
+	MongoIDs        []string    // Dokumen yang lolos filter status di Postgres (nil = tanpa batasan)
+	AchievementType string
+	Tag             string
+	Year            int
+}
+
+// SearchAchievementsMongo menjalankan $text search, urut skor tertinggi, maksimal limit dokumen
+func SearchAchievementsMongo(db *mongo.Database, f AchievementSearchFilter, limit int) ([]AchievementSearchDoc, error) {
+	filter := bson.M{
+		"$text":     bson.M{"$search": f.Query},
+		"deletedAt": bson.M{"$exists": false},
+	}
+	if f.StudentIDs != nil {
+		filter["studentId"] = bson.M{"$in": f.StudentIDs}
+	}
+	if f.MongoIDs != nil {
+		objIDs := make([]primitive.ObjectID, 0, len(f.MongoIDs))
+		for _, hexID := range f.MongoIDs {
+			if objID, err := primitive.ObjectIDFromHex(hexID); err == nil {
+				objIDs = append(objIDs, objID)
+			}
+		}
+		filter["_id"] = bson.M{"$in": objIDs}
+	}
+	if f.AchievementType != "" {
+		filter["achievementType"] = f.AchievementType
+	}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	if f.Year > 0 {
+		from := time.Date(f.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+		filter["createdAt"] = bson.M{"$gte": from, "$lt": from.AddDate(1, 0, 0)}
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetLimit(int64(limit))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	docs := []AchievementSearchDoc{}
+	if err := cursor.All(ctx, &docs); err != nil { return nil, err }
+	return docs, nil
+}
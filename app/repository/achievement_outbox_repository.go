@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// EnqueueAchievementOutbox menulis operasi outbox. Berikan *sql.Tx agar ikut transaksi perubahan reference.
+func EnqueueAchievementOutbox(db sqlExecer, achievementID *uuid.UUID, mongoID string, op model.OutboxOperation) error {
+	return EnqueueAchievementOutboxPayload(db, achievementID, mongoID, op, nil)
+}
+
+// EnqueueAchievementOutboxPayload: Seperti EnqueueAchievementOutbox, dengan data operasi (JSON)
+func EnqueueAchievementOutboxPayload(db sqlExecer, achievementID *uuid.UUID, mongoID string, op model.OutboxOperation, payload []byte) error {
+	now := time.Now()
+	_, err := db.Exec(`
+		INSERT INTO achievement_outbox (id, achievement_id, mongo_achievement_id, operation, payload, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $6)
+	`, uuid.New(), achievementID, mongoID, op, payload, now)
+	return err
+}
+
+// ProcessDueAchievementOutbox mengunci batch outbox yang jatuh tempo (FOR UPDATE SKIP LOCKED, aman untuk
+// beberapa instance) lalu menjalankan handle per item. Item sukses ditandai processed, yang gagal dijadwalkan ulang.
+func ProcessDueAchievementOutbox(db *sql.DB, limit int, handle func(model.AchievementOutbox) error, backoff func(attempts int) time.Duration) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, achievement_id, mongo_achievement_id, operation, payload, attempts, last_error, next_attempt_at, created_at
+		FROM achievement_outbox
+		WHERE processed_at IS NULL AND next_attempt_at <= NOW()
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var items []model.AchievementOutbox
+	for rows.Next() {
+		var o model.AchievementOutbox
+		var payload []byte
+		if err := rows.Scan(&o.ID, &o.AchievementID, &o.MongoAchievementID, &o.Operation, &payload, &o.Attempts, &o.LastError, &o.NextAttemptAt, &o.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		o.Payload = payload
+		items = append(items, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, o := range items {
+		if handleErr := handle(o); handleErr != nil {
+			msg := handleErr.Error()
+			_, err = tx.Exec(`
+				UPDATE achievement_outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3
+			`, msg, time.Now().Add(backoff(o.Attempts+1)), o.ID)
+		} else {
+			_, err = tx.Exec(`UPDATE achievement_outbox SET processed_at = NOW(), last_error = NULL WHERE id = $1`, o.ID)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(items), tx.Commit()
+}
@@ -2,45 +2,156 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"project-uas/app/model"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-// Ambil semua (Untuk Admin)
-func GetAllAchievementReferences(db *sql.DB) ([]model.AchievementReference, error) {
-	// TAMBAHAN: Filter status != 'deleted' dan select deleted_at
-	return fetchAchievements(db, `
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
-		       verified_at, verified_by, rejection_note, created_at, updated_at, deleted_at
-		FROM achievement_references 
-		WHERE status != 'deleted' 
-		ORDER BY created_at DESC
-	`)
+// AchievementListScope: Batas data list sesuai aktor (kosong = semua, untuk admin)
+type AchievementListScope struct {
+	StudentID *uuid.UUID // Mahasiswa: miliknya saja
+	AdvisorID *uuid.UUID // Dosen wali: milik mahasiswa bimbingan (kecuali draft)
+	MongoIDs  []string   // Hasil filter konten Mongo (nil = tanpa batasan, kosong = tidak ada yang cocok)
 }
 
-// Ambil berdasarkan Student ID
-func GetAchievementReferencesByStudentID(db *sql.DB, studentID uuid.UUID) ([]model.AchievementReference, error) {
-	return fetchAchievements(db, `
+var achievementListSpec = ListSpec[model.AchievementReference]{
+	Select: `SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.submitted_at,
+		ar.verified_at, ar.verified_by, ar.rejection_note, ar.created_at, ar.updated_at, ar.deleted_at`,
+	From: `FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id`,
+	IDColumn: "ar.id",
+	Sorts: map[string]string{
+		"created_at": "ar.created_at", "updated_at": "ar.updated_at", "status": "ar.status",
+	},
+	DefaultSort: []model.SortField{{Field: "created_at", Desc: true}},
+	Filters: map[string]ListFilter{
+		"status":         {Column: "ar.status", Kind: FilterIn},
+		"student_id":     {Column: "ar.student_id", Kind: FilterEqual},
+		"program_study":  {Column: "s.program_study", Kind: FilterEqual},
+		"academic_year":  {Column: "s.academic_year", Kind: FilterEqual},
+		"department":     {Column: "l.department", Kind: FilterEqual},
+		"created_from":   {Column: "ar.created_at", Kind: FilterFrom},
+		"created_to":     {Column: "ar.created_at", Kind: FilterTo},
+		"submitted_from": {Column: "ar.submitted_at", Kind: FilterFrom},
+		"submitted_to":   {Column: "ar.submitted_at", Kind: FilterTo},
+		"verified_from":  {Column: "ar.verified_at", Kind: FilterFrom},
+		"verified_to":    {Column: "ar.verified_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.AchievementReference, error) {
+		var r model.AchievementReference
+		err := rows.Scan(&r.ID, &r.StudentID, &r.MongoAchievementID, &r.Status, &r.SubmittedAt, &r.VerifiedAt, &r.VerifiedBy, &r.RejectionNote, &r.CreatedAt, &r.UpdatedAt, &r.DeletedAt)
+		return r, err
+	},
+	SortValue: func(r model.AchievementReference, key string) interface{} {
+		switch key {
+		case "created_at":
+			return r.CreatedAt
+		case "updated_at":
+			return r.UpdatedAt
+		case "status":
+			return r.Status
+		}
+		return r.ID
+	},
+}
+
+// ListAchievementReferences: List reference aktif (bukan deleted) sesuai scope aktor + filter/sort/pagination
+func ListAchievementReferences(db *sql.DB, scope AchievementListScope, params model.ListParams) (*model.ListResult[model.AchievementReference], error) {
+	return runList(db, achievementListSpec, params, achievementScopeWhere(scope))
+}
+
+func achievementScopeWhere(scope AchievementListScope) *sqlWhere {
+	where := &sqlWhere{}
+	where.add("ar.status <> 'deleted'")
+	if scope.StudentID != nil {
+		where.add("ar.student_id = ?", *scope.StudentID)
+	}
+	if scope.AdvisorID != nil {
+		where.add("s.advisor_id = ?", *scope.AdvisorID)
+		where.add("ar.status <> 'draft'")
+	}
+	if scope.MongoIDs != nil {
+		where.add("ar.mongo_achievement_id = ANY(?)", pq.Array(scope.MongoIDs))
+	}
+	return where
+}
+
+// GetAchievementMongoIDsInScope: mongo_achievement_id reference aktif milik studentIDs (nil = semua mahasiswa),
+// berstatus salah satu statuses (kosong = semua) dan bukan salah satu exclude
+func GetAchievementMongoIDsInScope(db *sql.DB, studentIDs []uuid.UUID, statuses, exclude []model.AchievementStatus) ([]string, error) {
+	query := `SELECT mongo_achievement_id FROM achievement_references WHERE status <> 'deleted'`
+	args := []interface{}{}
+	if studentIDs != nil {
+		ids := make([]string, len(studentIDs))
+		for i, id := range studentIDs {
+			ids[i] = id.String()
+		}
+		args = append(args, pq.Array(ids))
+		query += fmt.Sprintf(" AND student_id = ANY($%d::uuid[])", len(args))
+	}
+	if len(statuses) > 0 {
+		args = append(args, pq.Array(statusStrings(statuses)))
+		query += fmt.Sprintf(" AND status = ANY($%d)", len(args))
+	}
+	if len(exclude) > 0 {
+		args = append(args, pq.Array(statusStrings(exclude)))
+		query += fmt.Sprintf(" AND status <> ALL($%d)", len(args))
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func statusStrings(statuses []model.AchievementStatus) []string {
+	out := make([]string, len(statuses))
+	for i, s := range statuses {
+		out[i] = string(s)
+	}
+	return out
+}
+
+// GetAchievementReferencesByMongoIDs: Reference aktif (bukan deleted) untuk banyak dokumen Mongo, di-key mongo ID
+func GetAchievementReferencesByMongoIDs(db *sql.DB, mongoIDs []string) (map[string]model.AchievementReference, error) {
+	refs, err := fetchAchievements(db, `
 		SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
 		       verified_at, verified_by, rejection_note, created_at, updated_at, deleted_at
-		FROM achievement_references 
-		WHERE student_id = $1 AND status != 'deleted' 
-		ORDER BY created_at DESC
-	`, studentID)
+		FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1) AND status <> 'deleted'
+	`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]model.AchievementReference, len(refs))
+	for _, r := range refs {
+		result[r.MongoAchievementID] = r
+	}
+	return result, nil
 }
 
-// Ambil berdasarkan Advisor ID
-func GetAchievementReferencesByAdvisorID(db *sql.DB, advisorID uuid.UUID) ([]model.AchievementReference, error) {
+// GetAllAchievementReferencesForReconcile: Semua reference termasuk yang sudah soft delete
+func GetAllAchievementReferencesForReconcile(db *sql.DB) ([]model.AchievementReference, error) {
 	return fetchAchievements(db, `
-		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.submitted_at, 
-		       ar.verified_at, ar.verified_by, ar.rejection_note, ar.created_at, ar.updated_at, ar.deleted_at
-		FROM achievement_references ar
-		JOIN students s ON ar.student_id = s.id
-		WHERE s.advisor_id = $1 AND ar.status != 'draft' AND ar.status != 'deleted'
-		ORDER BY ar.created_at DESC
-	`, advisorID)
+		SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
+		       verified_at, verified_by, rejection_note, created_at, updated_at, deleted_at
+		FROM achievement_references
+	`)
 }
 
 // Helper function untuk scan rows
@@ -84,35 +195,277 @@ func GetAchievementReferenceByID(db *sql.DB, id uuid.UUID) (*model.AchievementRe
 	return &r, nil
 }
 
-func CreateAchievementReference(db *sql.DB, r *model.AchievementReference) error {
+// CreateAchievementReference menyimpan reference baru beserta event pembuatan (draft) dalam satu transaksi
+func CreateAchievementReference(db *sql.DB, r *model.AchievementReference, event *model.AchievementStatusEvent) error {
 	r.ID = uuid.New()
 	now := time.Now()
 	r.CreatedAt = now
 	r.UpdatedAt = now
-	_, err := db.Exec(`
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO achievement_references (id, student_id, mongo_achievement_id, status, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`, r.ID, r.StudentID, r.MongoAchievementID, r.Status, r.CreatedAt, r.UpdatedAt)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := insertStatusEvent(tx, r.ID, r.Status, now, event); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// Update Status Generic
-func UpdateAchievementStatus(db *sql.DB, r *model.AchievementReference) error {
+// ErrAchievementStatusChanged: Status di DB sudah berubah sejak dibaca (kalah race dengan request lain)
+var ErrAchievementStatusChanged = errors.New("status prestasi sudah berubah")
+
+// TransitionAchievementStatus: Update status + event log dalam satu transaksi, hanya jika status di DB
+// masih from (ErrAchievementStatusChanged jika tidak). Operasi outbox (opsional) ditulis di transaksi yang sama,
+// sehingga perubahan Mongo hanya dijalankan setelah transisi commit.
+func TransitionAchievementStatus(db *sql.DB, r *model.AchievementReference, from model.AchievementStatus, event *model.AchievementStatusEvent, outbox *model.AchievementOutbox) error {
 	r.UpdatedAt = time.Now()
-	_, err := db.Exec(`
-		UPDATE achievement_references 
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE achievement_references
 		SET status = $1, submitted_at = $2, verified_at = $3, verified_by = $4, rejection_note = $5, updated_at = $6
-		WHERE id = $7
-	`, r.Status, r.SubmittedAt, r.VerifiedAt, r.VerifiedBy, r.RejectionNote, r.UpdatedAt, r.ID)
-	return err
+		WHERE id = $7 AND status = $8
+	`, r.Status, r.SubmittedAt, r.VerifiedAt, r.VerifiedBy, r.RejectionNote, r.UpdatedAt, r.ID, from)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAchievementStatusChanged
+	}
+
+	if err := insertStatusEvent(tx, r.ID, r.Status, r.UpdatedAt, event); err != nil {
+		return err
+	}
+	if outbox != nil {
+		if err := EnqueueAchievementOutboxPayload(tx, &r.ID, r.MongoAchievementID, outbox.Operation, outbox.Payload); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func DeleteAchievementReference(db *sql.DB, id uuid.UUID) error {
+// DeleteAchievementReference: Soft delete reference + event log, dan antrekan soft delete konten Mongo
+// di outbox dalam transaksi yang sama (dijalankan worker outbox, lihat service/achievement_outbox.go).
+// Hanya berlaku jika status di DB masih ref.Status (ErrAchievementStatusChanged jika tidak).
+func DeleteAchievementReference(db *sql.DB, ref *model.AchievementReference, event *model.AchievementStatusEvent) error {
+	id := ref.ID
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	// MENGUBAH QUERY DELETE MENJADI UPDATE
-	_, err := db.Exec(`
+	res, err := tx.Exec(`
 		UPDATE achievement_references 
 		SET status = 'deleted', deleted_at = NOW(), updated_at = NOW()
-		WHERE id = $1
-	`, id)
+		WHERE id = $1 AND status = $2
+	`, id, ref.Status)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAchievementStatusChanged
+	}
+
+	if err := insertStatusEvent(tx, id, model.StatusDeleted, time.Now(), event); err != nil {
+		return err
+	}
+	if err := EnqueueAchievementOutbox(tx, &id, ref.MongoAchievementID, model.OutboxMongoSoftDelete); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// --- Trash (soft delete) ---
+
+const trashSelect = `SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.submitted_at,
+		ar.verified_at, ar.verified_by, ar.rejection_note, ar.created_at, ar.updated_at, ar.deleted_at,
+		(SELECT e.old_status FROM achievement_status_events e
+		 WHERE e.achievement_id = ar.id AND e.new_status = 'deleted'
+		 ORDER BY e.created_at DESC, e.id DESC LIMIT 1)`
+
+func scanTrashedAchievement(row interface{ Scan(...interface{}) error }) (model.TrashedAchievement, error) {
+	var t model.TrashedAchievement
+	r := &t.AchievementReference
+	err := row.Scan(&r.ID, &r.StudentID, &r.MongoAchievementID, &r.Status, &r.SubmittedAt, &r.VerifiedAt, &r.VerifiedBy, &r.RejectionNote, &r.CreatedAt, &r.UpdatedAt, &r.DeletedAt, &t.PreviousStatus)
+	return t, err
+}
+
+var trashListSpec = ListSpec[model.TrashedAchievement]{
+	Select:      trashSelect,
+	From:        "FROM achievement_references ar",
+	IDColumn:    "ar.id",
+	Sorts:       map[string]string{"deleted_at": "ar.deleted_at", "created_at": "ar.created_at"},
+	DefaultSort: []model.SortField{{Field: "deleted_at", Desc: true}},
+	Filters: map[string]ListFilter{
+		"student_id":   {Column: "ar.student_id", Kind: FilterEqual},
+		"deleted_from": {Column: "ar.deleted_at", Kind: FilterFrom},
+		"deleted_to":   {Column: "ar.deleted_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.TrashedAchievement, error) { return scanTrashedAchievement(rows) },
+	SortValue: func(t model.TrashedAchievement, key string) interface{} {
+		switch key {
+		case "deleted_at":
+			return t.DeletedAt
+		case "created_at":
+			return t.CreatedAt
+		}
+		return t.ID
+	},
+}
+
+// GetDeletedAchievementReferences: Isi trash beserta status sebelum dihapus
+func GetDeletedAchievementReferences(db *sql.DB, params model.ListParams) (*model.ListResult[model.TrashedAchievement], error) {
+	scope := &sqlWhere{}
+	scope.add("ar.status = 'deleted' AND ar.deleted_at IS NOT NULL")
+	return runList(db, trashListSpec, params, scope)
+}
+
+// GetDeletedAchievementReferenceByID: Seperti GetAchievementReferenceByID, tapi hanya untuk item di trash
+func GetDeletedAchievementReferenceByID(db *sql.DB, id uuid.UUID) (*model.TrashedAchievement, error) {
+	t, err := scanTrashedAchievement(db.QueryRow(trashSelect+`
+		FROM achievement_references ar
+		WHERE ar.id = $1 AND ar.status = 'deleted'
+	`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetAchievementReferenceStatus: Status saat ini tanpa filter deleted (dipakai worker outbox)
+func GetAchievementReferenceStatus(db *sql.DB, id uuid.UUID) (model.AchievementStatus, error) {
+	var status model.AchievementStatus
+	err := db.QueryRow(`SELECT status FROM achievement_references WHERE id = $1`, id).Scan(&status)
+	return status, err
+}
+
+// RestoreAchievementReference mengembalikan reference dari trash ke status r.Status,
+// menulis event log, dan mengantrekan restore konten Mongo dalam satu transaksi
+func RestoreAchievementReference(db *sql.DB, r *model.AchievementReference, event *model.AchievementStatusEvent) error {
+	r.UpdatedAt = time.Now()
+	r.DeletedAt = nil
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE achievement_references SET status = $1, deleted_at = NULL, updated_at = $2
+		WHERE id = $3 AND status = 'deleted'
+	`, r.Status, r.UpdatedAt, r.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := insertStatusEvent(tx, r.ID, r.Status, r.UpdatedAt, event); err != nil {
+		return err
+	}
+	if err := EnqueueAchievementOutbox(tx, &r.ID, r.MongoAchievementID, model.OutboxMongoRestore); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPurgeableAchievementReferences: Item trash yang dihapus sebelum `before`
+func GetPurgeableAchievementReferences(db *sql.DB, before time.Time, limit int) ([]model.AchievementReference, error) {
+	return fetchAchievements(db, `
+		SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
+		       verified_at, verified_by, rejection_note, created_at, updated_at, deleted_at
+		FROM achievement_references
+		WHERE status = 'deleted' AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`, before, limit)
+}
+
+// PurgeAchievementReference menghapus permanen reference beserta event log & outbox-nya.
+// Konten Mongo dan file lampiran harus sudah dihapus sebelumnya.
+func PurgeAchievementReference(db *sql.DB, id uuid.UUID) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range []string{
+		`DELETE FROM achievement_status_events WHERE achievement_id = $1`,
+		`DELETE FROM achievement_outbox WHERE achievement_id = $1`,
+		`DELETE FROM achievement_references WHERE id = $1 AND status = 'deleted'`,
+	} {
+		if _, err := tx.Exec(q, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertStatusEvent menulis event log perubahan status (dipanggil di dalam transaksi)
+func insertStatusEvent(tx *sql.Tx, achievementID uuid.UUID, newStatus model.AchievementStatus, at time.Time, e *model.AchievementStatusEvent) error {
+	if e == nil {
+		e = &model.AchievementStatusEvent{}
+	}
+	e.ID = uuid.New()
+	e.AchievementID = achievementID
+	e.NewStatus = newStatus
+	e.CreatedAt = at
+
+	_, err := tx.Exec(`
+		INSERT INTO achievement_status_events
+			(id, achievement_id, old_status, new_status, actor_user_id, actor_role, note, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, e.ID, e.AchievementID, e.OldStatus, e.NewStatus, e.ActorUserID, e.ActorRole, e.Note, e.RequestID, e.CreatedAt)
 	return err
-}
\ No newline at end of file
+}
+
+// GetAchievementHistory mengambil event log status beserta nama aktor, urut dari yang terlama
+func GetAchievementHistory(db *sql.DB, achievementID uuid.UUID) ([]model.AchievementHistoryResponse, error) {
+	rows, err := db.Query(`
+		SELECT e.old_status, e.new_status, e.created_at, e.actor_user_id,
+		       COALESCE(u.full_name, ''), e.actor_role, e.note, e.request_id
+		FROM achievement_status_events e
+		LEFT JOIN users u ON u.id = e.actor_user_id
+		WHERE e.achievement_id = $1
+		ORDER BY e.created_at ASC, e.id ASC
+	`, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.AchievementHistoryResponse{}
+	for rows.Next() {
+		var h model.AchievementHistoryResponse
+		if err := rows.Scan(&h.FromStatus, &h.Status, &h.Timestamp, &h.ActorID, &h.Actor, &h.ActorRole, &h.Note, &h.RequestID); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const revisionCollectionName = "achievement_revisions"
+
+// InsertAchievementRevision menyimpan snapshot konten dengan nomor revisi berikutnya
+func InsertAchievementRevision(db *mongo.Database, rev *model.AchievementRevision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	for attempt := 0; attempt < maxSequenceInsertAttempts; attempt++ {
+		var latest *model.AchievementRevision
+		latest, err = GetLatestAchievementRevision(db, rev.AchievementID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		rev.Revision = 1
+		if latest != nil {
+			rev.Revision = latest.Revision + 1
+		}
+		rev.ID = primitive.NewObjectID()
+		rev.CreatedAt = time.Now()
+
+		_, err = db.Collection(revisionCollectionName).InsertOne(ctx, rev)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// GetAchievementRevisions: Semua revisi milik prestasi, urut dari revisi pertama
+func GetAchievementRevisions(db *mongo.Database, achievementID uuid.UUID) ([]model.AchievementRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"revision": 1})
+	cursor, err := db.Collection(revisionCollectionName).Find(ctx, bson.M{"achievementId": achievementID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []model.AchievementRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func GetAchievementRevision(db *mongo.Database, achievementID uuid.UUID, revision int) (*model.AchievementRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result model.AchievementRevision
+	err := db.Collection(revisionCollectionName).FindOne(ctx, bson.M{"achievementId": achievementID, "revision": revision}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetLatestAchievementRevision(db *mongo.Database, achievementID uuid.UUID) (*model.AchievementRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.FindOne().SetSort(bson.M{"revision": -1})
+	var result model.AchievementRevision
+	err := db.Collection(revisionCollectionName).FindOne(ctx, bson.M{"achievementId": achievementID}, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAchievementRevisions: Hapus semua revisi milik prestasi (purge permanen)
+func DeleteAchievementRevisions(db *mongo.Database, achievementID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.Collection(revisionCollectionName).DeleteMany(ctx, bson.M{"achievementId": achievementID})
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const achievementTypeColumns = "id, code, name, description, details_schema, is_active, created_at, updated_at"
+
+func scanAchievementType(row interface{ Scan(...interface{}) error }) (model.AchievementType, error) {
+	var t model.AchievementType
+	var schema []byte
+	err := row.Scan(&t.ID, &t.Code, &t.Name, &t.Description, &schema, &t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+	t.DetailsSchema = schema
+	return t, err
+}
+
+var achievementTypeListSpec = ListSpec[model.AchievementType]{
+	Select:      "SELECT " + achievementTypeColumns,
+	From:        "FROM achievement_types",
+	IDColumn:    "id",
+	Sorts:       map[string]string{"code": "code", "name": "name", "created_at": "created_at"},
+	DefaultSort: []model.SortField{{Field: "name"}},
+	Filters: map[string]ListFilter{
+		"name":      {Column: "name", Kind: FilterILike},
+		"is_active": {Column: "is_active", Kind: FilterEqual},
+	},
+	Scan: func(rows *sql.Rows) (model.AchievementType, error) {
+		return scanAchievementType(rows)
+	},
+	SortValue: func(t model.AchievementType, key string) interface{} {
+		switch key {
+		case "code":
+			return t.Code
+		case "name":
+			return t.Name
+		case "created_at":
+			return t.CreatedAt
+		}
+		return t.ID
+	},
+}
+
+func GetAllAchievementTypes(db *sql.DB, params model.ListParams) (*model.ListResult[model.AchievementType], error) {
+	return runList(db, achievementTypeListSpec, params, nil)
+}
+
+func GetAchievementTypeByCode(db *sql.DB, code string) (*model.AchievementType, error) {
+	t, err := scanAchievementType(db.QueryRow("SELECT "+achievementTypeColumns+" FROM achievement_types WHERE code = $1", code))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func CreateAchievementType(db *sql.DB, t *model.AchievementType) error {
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	_, err := db.Exec(`
+        INSERT INTO achievement_types (id, code, name, description, details_schema, is_active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `, t.ID, t.Code, t.Name, t.Description, []byte(t.DetailsSchema), t.IsActive, t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+func UpdateAchievementType(db *sql.DB, t *model.AchievementType) error {
+	t.UpdatedAt = time.Now()
+	_, err := db.Exec(`
+        UPDATE achievement_types
+        SET name = $1, description = $2, details_schema = $3, is_active = $4, updated_at = $5
+        WHERE id = $6
+    `, t.Name, t.Description, []byte(t.DetailsSchema), t.IsActive, t.UpdatedAt, t.ID)
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const versionCollectionName = "achievement_versions"
+
+// Percobaan ulang penomoran saat nomor yang sama dipakai penulis lain (index unik achievementId + nomor)
+const maxSequenceInsertAttempts = 5
+
+// InsertAchievementVersion menyimpan snapshot konten dengan nomor versi berikutnya
+func InsertAchievementVersion(db *mongo.Database, v *model.AchievementVersion) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	for attempt := 0; attempt < maxSequenceInsertAttempts; attempt++ {
+		var latest *model.AchievementVersion
+		latest, err = GetLatestAchievementVersion(db, v.AchievementID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		v.Version = 1
+		if latest != nil {
+			v.Version = latest.Version + 1
+		}
+		v.ID = primitive.NewObjectID()
+		v.CreatedAt = time.Now()
+
+		_, err = db.Collection(versionCollectionName).InsertOne(ctx, v)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// GetAchievementVersions: Semua versi milik prestasi, urut dari versi pertama
+func GetAchievementVersions(db *mongo.Database, achievementID uuid.UUID) ([]model.AchievementVersion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"version": 1})
+	cursor, err := db.Collection(versionCollectionName).Find(ctx, bson.M{"achievementId": achievementID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []model.AchievementVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func GetAchievementVersion(db *mongo.Database, achievementID uuid.UUID, version int) (*model.AchievementVersion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result model.AchievementVersion
+	err := db.Collection(versionCollectionName).FindOne(ctx, bson.M{"achievementId": achievementID, "version": version}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetLatestAchievementVersion(db *mongo.Database, achievementID uuid.UUID) (*model.AchievementVersion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+	var result model.AchievementVersion
+	err := db.Collection(versionCollectionName).FindOne(ctx, bson.M{"achievementId": achievementID}, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAchievementVersions: Hapus semua versi milik prestasi (purge permanen)
+func DeleteAchievementVersions(db *mongo.Database, achievementID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.Collection(versionCollectionName).DeleteMany(ctx, bson.M{"achievementId": achievementID})
+	return err
+}
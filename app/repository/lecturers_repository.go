@@ -8,27 +8,45 @@ import (
 	"github.com/google/uuid"
 )
 
-func GetAllLecturers(db *sql.DB) ([]model.Lecturer, error) {
-	rows, err := db.Query(`
-        SELECT id, user_id, lecturer_id, department, created_at
-        FROM lecturers
-        ORDER BY created_at DESC
-    `)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var list []model.Lecturer
-	for rows.Next() {
+var lecturerListSpec = ListSpec[model.Lecturer]{
+	Select:   "SELECT id, user_id, lecturer_id, department, created_at",
+	From:     "FROM lecturers",
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"lecturer_id": "lecturer_id", "department": "department", "created_at": "created_at",
+	},
+	DefaultSort: []model.SortField{{Field: "created_at", Desc: true}},
+	Filters: map[string]ListFilter{
+		"lecturer_id":  {Column: "lecturer_id", Kind: FilterILike},
+		"department":   {Column: "department", Kind: FilterEqual},
+		"created_from": {Column: "created_at", Kind: FilterFrom},
+		"created_to":   {Column: "created_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.Lecturer, error) {
 		var l model.Lecturer
-		if err := rows.Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.CreatedAt); err != nil {
-			return nil, err
+		err := rows.Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.CreatedAt)
+		return l, err
+	},
+	SortValue: func(l model.Lecturer, key string) interface{} {
+		switch key {
+		case "lecturer_id":
+			return l.LecturerID
+		case "department":
+			return l.Department
+		case "created_at":
+			return l.CreatedAt
 		}
-		list = append(list, l)
-	}
+		return l.ID
+	},
+}
 
-	return list, nil
+// GetAllLecturers: Daftar dosen; lecturerID != nil membatasi ke satu dosen (PK lecturers)
+func GetAllLecturers(db *sql.DB, params model.ListParams, lecturerID *uuid.UUID) (*model.ListResult[model.Lecturer], error) {
+	where := &sqlWhere{}
+	if lecturerID != nil {
+		where.add("id = ?", *lecturerID)
+	}
+	return runList(db, lecturerListSpec, params, where)
 }
 
 // GetLecturerByID (Sebenarnya GetByUserID)
@@ -47,32 +65,30 @@ func GetLecturerByID(db *sql.DB, userID uuid.UUID) (*model.Lecturer, error) {
 	return &l, nil
 }
 
-func GetAdviseesByLecturerID(db *sql.DB, lecturerID uuid.UUID) ([]model.Student, error) {
-	rows, err := db.Query(`
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
-        FROM students
-        WHERE advisor_id = $1
-        ORDER BY created_at DESC
-    `, lecturerID)
+// GetAdviseesByLecturerID: Mahasiswa bimbingan (lecturerID = PK tabel lecturers)
+func GetAdviseesByLecturerID(db *sql.DB, lecturerID uuid.UUID, params model.ListParams) (*model.ListResult[model.Student], error) {
+	scope := &sqlWhere{}
+	scope.add("s.advisor_id = ?", lecturerID)
+	return runList(db, studentListSpec, params, scope)
+}
+
+// GetAdviseeStudentIDs: PK students milik mahasiswa bimbingan (untuk scope pencarian)
+func GetAdviseeStudentIDs(db *sql.DB, lecturerID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := db.Query(`SELECT id FROM students WHERE advisor_id = $1`, lecturerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var students []model.Student
+	ids := []uuid.UUID{}
 	for rows.Next() {
-		var s model.Student
-		if err := rows.Scan(
-			&s.ID, &s.UserID, &s.StudentID,
-			&s.ProgramStudy, &s.AcademicYear,
-			&s.AdvisorID, &s.CreatedAt,
-		); err != nil {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		students = append(students, s)
+		ids = append(ids, id)
 	}
-
-	return students, nil
+	return ids, rows.Err()
 }
 
 // CreateLecturer
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project-uas/app/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrInvalidListQuery: Parameter sort / filter / cursor tidak valid (-> 400)
+var ErrInvalidListQuery = errors.New("parameter list tidak valid")
+
+// Jenis filter yang bisa dipakai di ListSpec
+type FilterKind int
+
+const (
+	FilterEqual FilterKind = iota // kolom = nilai
+	FilterIn                      // kolom = ANY(nilai dipisah koma)
+	FilterILike                   // kolom ILIKE %nilai%
+	FilterFrom                    // kolom >= tanggal (YYYY-MM-DD / RFC3339)
+	FilterTo                      // kolom <= tanggal (tanggal saja = sampai akhir hari)
+)
+
+type ListFilter struct {
+	Column string
+	Kind   FilterKind
+}
+
+// ListSpec mendeskripsikan satu endpoint list. Semua nama kolom berasal dari spec (bukan dari
+// input user), nilai dari user selalu dikirim sebagai parameter ($n).
+type ListSpec[T any] struct {
+	Select      string                // "SELECT <kolom>"
+	From        string                // "FROM <tabel> [JOIN ...]"
+	IDColumn    string                // Tiebreaker unik untuk urutan & cursor
+	Sorts       map[string]string     // key sort -> kolom (tidak boleh NULL agar cursor benar)
+	DefaultSort []model.SortField
+	Filters     map[string]ListFilter // nama query param -> kolom
+	Scan        func(*sql.Rows) (T, error)
+	SortValue   func(item T, key string) interface{} // Nilai key sort (dan "id") untuk cursor
+}
+
+// sqlWhere menyusun kondisi WHERE; tanda ? di kondisi diganti $n sesuai urutan argumen
+type sqlWhere struct {
+	conds []string
+	args  []interface{}
+}
+
+func (w *sqlWhere) add(cond string, args ...interface{}) {
+	for _, a := range args {
+		w.args = append(w.args, a)
+		cond = strings.Replace(cond, "?", "$"+strconv.Itoa(len(w.args)), 1)
+	}
+	w.conds = append(w.conds, cond)
+}
+
+func (w *sqlWhere) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+type listCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// runList menjalankan query list: filter, total, sort, lalu halaman (page/limit) atau cursor (after).
+// scope berisi kondisi tetap dari pemanggil (mis. hanya milik mahasiswa tertentu).
+func runList[T any](db *sql.DB, spec ListSpec[T], params model.ListParams, scope *sqlWhere) (*model.ListResult[T], error) {
+	where := &sqlWhere{}
+	if scope != nil {
+		where.conds = append(where.conds, scope.conds...)
+		where.args = append(where.args, scope.args...)
+	}
+
+	for name, raw := range params.Filters {
+		f, ok := spec.Filters[name]
+		if !ok || raw == "" {
+			continue
+		}
+		if err := addFilter(where, f, raw); err != nil {
+			return nil, fmt.Errorf("%w: filter %s: %v", ErrInvalidListQuery, name, err)
+		}
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) "+spec.From+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	sorts := params.Sort
+	if len(sorts) == 0 {
+		sorts = spec.DefaultSort
+	}
+	columns := make([]string, len(sorts))
+	sortKey := make([]string, len(sorts))
+	for i, s := range sorts {
+		col, ok := spec.Sorts[s.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: sort %q tidak didukung", ErrInvalidListQuery, s.Field)
+		}
+		columns[i] = col
+		sortKey[i] = s.Field
+		if s.Desc {
+			sortKey[i] = "-" + s.Field
+		}
+	}
+	signature := strings.Join(sortKey, ",")
+
+	if params.After != "" {
+		values, err := decodeCursor(params.After, signature, len(sorts)+1)
+		if err != nil {
+			return nil, err
+		}
+		where.add(keysetCondition(columns, sorts, spec.IDColumn, len(where.args)), values...)
+	}
+
+	order := make([]string, 0, len(sorts)+1)
+	for i, s := range sorts {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		order = append(order, columns[i]+" "+dir)
+	}
+	order = append(order, spec.IDColumn+" ASC")
+
+	query := spec.Select + " " + spec.From + where.String() + " ORDER BY " + strings.Join(order, ", ")
+	args := append([]interface{}{}, where.args...)
+	args = append(args, params.Limit+1)
+	query += " LIMIT $" + strconv.Itoa(len(args))
+	if params.After == "" && params.Page > 1 {
+		args = append(args, (params.Page-1)*params.Limit)
+		query += " OFFSET $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &model.ListResult[T]{Items: []T{}, Total: total}
+	for rows.Next() {
+		item, err := spec.Scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) > params.Limit {
+		result.Items = result.Items[:params.Limit]
+		last := result.Items[len(result.Items)-1]
+		values := make([]interface{}, 0, len(sorts)+1)
+		for _, s := range sorts {
+			values = append(values, spec.SortValue(last, s.Field))
+		}
+		values = append(values, spec.SortValue(last, "id"))
+		raw, _ := json.Marshal(listCursor{Sort: signature, Values: values})
+		result.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return result, nil
+}
+
+func addFilter(where *sqlWhere, f ListFilter, raw string) error {
+	switch f.Kind {
+	case FilterEqual:
+		where.add(f.Column+" = ?", raw)
+	case FilterIn:
+		where.add(f.Column+" = ANY(?)", pq.Array(strings.Split(raw, ",")))
+	case FilterILike:
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(raw)
+		where.add(f.Column+" ILIKE ?", "%"+escaped+"%")
+	case FilterFrom, FilterTo:
+		t, dateOnly, err := parseFilterTime(raw)
+		if err != nil {
+			return err
+		}
+		if f.Kind == FilterFrom {
+			where.add(f.Column+" >= ?", t)
+		} else if dateOnly {
+			where.add(f.Column+" < ?", t.AddDate(0, 0, 1))
+		} else {
+			where.add(f.Column+" <= ?", t)
+		}
+	}
+	return nil
+}
+
+func parseFilterTime(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false, errors.New("format tanggal harus YYYY-MM-DD atau RFC3339")
+	}
+	return t, false, nil
+}
+
+// keysetCondition: (c1 > v1) OR (c1 = v1 AND c2 < v2) OR ... OR (c1 = v1 AND ... AND id > vid)
+func keysetCondition(columns []string, sorts []model.SortField, idColumn string, argOffset int) string {
+	cols := append(append([]string{}, columns...), idColumn)
+	ops := make([]string, len(cols))
+	for i := range sorts {
+		ops[i] = ">"
+		if sorts[i].Desc {
+			ops[i] = "<"
+		}
+	}
+	ops[len(ops)-1] = ">"
+
+	placeholder := func(i int) string { return "$" + strconv.Itoa(argOffset+i+1) }
+	var ors []string
+	for i := range cols {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, cols[j]+" = "+placeholder(j))
+		}
+		ands = append(ands, cols[i]+" "+ops[i]+" "+placeholder(i))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	// Placeholder sudah ditulis langsung, jadi kondisi ini tidak memakai tanda ?
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+func decodeCursor(raw, signature string, n int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor tidak valid", ErrInvalidListQuery)
+	}
+	var cur listCursor
+	if err := json.Unmarshal(data, &cur); err != nil || len(cur.Values) != n {
+		return nil, fmt.Errorf("%w: cursor tidak valid", ErrInvalidListQuery)
+	}
+	if cur.Sort != signature {
+		return nil, fmt.Errorf("%w: cursor dibuat dengan sort berbeda", ErrInvalidListQuery)
+	}
+	return cur.Values, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"project-uas/app/model"
+)
+
+func TestKeysetCondition_MixedDirections(t *testing.T) {
+	sorts := []model.SortField{{Field: "status"}, {Field: "created_at", Desc: true}}
+	got := keysetCondition([]string{"ar.status", "ar.created_at"}, sorts, "ar.id", 2)
+
+	want := "((ar.status > $3) OR (ar.status = $3 AND ar.created_at < $4) OR (ar.status = $3 AND ar.created_at = $4 AND ar.id > $5))"
+	if got != want {
+		t.Fatalf("unexpected condition:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestSqlWhere_NumbersPlaceholders(t *testing.T) {
+	w := &sqlWhere{}
+	w.add("ar.status <> 'deleted'")
+	w.add("s.advisor_id = ?", "x")
+	if err := addFilter(w, ListFilter{Column: "ar.created_at", Kind: FilterTo}, "2026-01-31"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := " WHERE ar.status <> 'deleted' AND s.advisor_id = $1 AND ar.created_at < $2"
+	if w.String() != want {
+		t.Fatalf("unexpected where: %s", w.String())
+	}
+	if len(w.args) != 2 {
+		t.Fatalf("expected 2 args, got %d", len(w.args))
+	}
+}
+
+func TestDecodeCursor_RejectsDifferentSort(t *testing.T) {
+	if _, err := decodeCursor("not-base64!", "-created_at", 2); !errors.Is(err, ErrInvalidListQuery) {
+		t.Fatalf("expected ErrInvalidListQuery, got %v", err)
+	}
+	// {"s":"name","v":["a","b"]}
+	cursor := "eyJzIjoibmFtZSIsInYiOlsiYSIsImIiXX0"
+	if _, err := decodeCursor(cursor, "-created_at", 2); !errors.Is(err, ErrInvalidListQuery) {
+		t.Fatalf("expected sort mismatch error, got %v", err)
+	}
+	if v, err := decodeCursor(cursor, "name", 2); err != nil || len(v) != 2 {
+		t.Fatalf("expected valid cursor, got %v %v", v, err)
+	}
+}
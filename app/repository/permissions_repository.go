@@ -7,26 +7,37 @@ import (
 	"github.com/google/uuid"
 )
 
-func GetAllPermissions(db *sql.DB) ([]model.Permission, error) {
-	
-	rows, err := db.Query(`
-        SELECT id, name, resource, action, description 
-        FROM permissions ORDER BY resource, action, name ASC
-    `)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var permissions []model.Permission
-	for rows.Next() {
+var permissionListSpec = ListSpec[model.Permission]{
+	Select:      "SELECT id, name, resource, action, description",
+	From:        "FROM permissions",
+	IDColumn:    "id",
+	Sorts:       map[string]string{"name": "name", "resource": "resource", "action": "action"},
+	DefaultSort: []model.SortField{{Field: "resource"}, {Field: "action"}, {Field: "name"}},
+	Filters: map[string]ListFilter{
+		"resource": {Column: "resource", Kind: FilterEqual},
+		"action":   {Column: "action", Kind: FilterEqual},
+		"name":     {Column: "name", Kind: FilterILike},
+	},
+	Scan: func(rows *sql.Rows) (model.Permission, error) {
 		var p model.Permission
-		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
-			return nil, err
+		err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+		return p, err
+	},
+	SortValue: func(p model.Permission, key string) interface{} {
+		switch key {
+		case "name":
+			return p.Name
+		case "resource":
+			return p.Resource
+		case "action":
+			return p.Action
 		}
-		permissions = append(permissions, p)
-	}
-	return permissions, nil
+		return p.ID
+	},
+}
+
+func GetAllPermissions(db *sql.DB, params model.ListParams) (*model.ListResult[model.Permission], error) {
+	return runList(db, permissionListSpec, params, nil)
 }
 
 func GetPermissionByID(db *sql.DB, id uuid.UUID) (*model.Permission, error) {
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func CreateRefreshToken(db *sql.DB, t *model.RefreshToken) error {
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now()
+	_, err := db.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, user_agent, ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, t.ID, t.UserID, t.FamilyID, t.TokenHash, t.UserAgent, t.IPAddress, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+func GetRefreshTokenByHash(db *sql.DB, tokenHash string) (*model.RefreshToken, error) {
+	var t model.RefreshToken
+	row := db.QueryRow(`
+		SELECT id, user_id, family_id, token_hash, user_agent, ip_address, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1
+	`, tokenHash)
+	err := row.Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.UserAgent, &t.IPAddress, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RotateRefreshToken menandai token lama sebagai "used" dan menyimpan token baru
+// dalam satu transaksi. Return false jika token lama ternyata sudah dipakai
+// (request paralel / reuse), sehingga token baru tidak disimpan.
+func RotateRefreshToken(db *sql.DB, oldID uuid.UUID, next *model.RefreshToken) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, oldID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	next.ID = uuid.New()
+	next.CreatedAt = time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, user_agent, ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, next.ID, next.UserID, next.FamilyID, next.TokenHash, next.UserAgent, next.IPAddress, next.ExpiresAt, next.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RevokeRefreshTokenFamily mencabut seluruh token dalam satu family (satu sesi login)
+func RevokeRefreshTokenFamily(db *sql.DB, familyID uuid.UUID) error {
+	_, err := db.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	return err
+}
+
+// RevokeUserRefreshTokens mencabut semua refresh token milik user (logout semua sesi)
+func RevokeUserRefreshTokens(db *sql.DB, userID uuid.UUID) error {
+	_, err := db.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
@@ -46,7 +46,7 @@ func CountAchievementsByStatus(db *sql.DB) (map[string]int, error) {
 
 // --- MONGODB STATS (AGGREGATION) ---
 
-// Hitung jumlah prestasi berdasarkan Type (Competition, Organization, dll)
+// Hitung jumlah prestasi berdasarkan Type (Competition, Organization, dll); prestasi di trash tidak dihitung
 func AggregateAchievementsByType(db *mongo.Database) (map[string]int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -55,6 +55,7 @@ func AggregateAchievementsByType(db *mongo.Database) (map[string]int, error) {
 
 	// PERBAIKAN: Menggunakan bson.M agar tidak kena linter error "unkeyed fields"
 	pipeline := bson.A{
+		bson.M{"$match": bson.M{"deletedAt": bson.M{"$exists": false}}},
 		bson.M{"$group": bson.M{
 			"_id":   "$achievementType",
 			"count": bson.M{"$sum": 1},
@@ -82,38 +83,3 @@ func AggregateAchievementsByType(db *mongo.Database) (map[string]int, error) {
 	}
 	return result, nil
 }
-
-// Hitung total poin mahasiswa tertentu (Sum Points)
-func SumStudentPoints(db *mongo.Database, studentUUIDStr string) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	collection := db.Collection("achievements")
-
-	// PERBAIKAN: Menggunakan bson.M untuk match dan group
-	pipeline := bson.A{
-		bson.M{"$match": bson.M{"studentId": studentUUIDStr}},
-		bson.M{"$group": bson.M{
-			"_id":         nil,
-			"totalPoints": bson.M{"$sum": "$points"},
-		}},
-	}
-
-	cursor, err := collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return 0, err
-	}
-	defer cursor.Close(ctx)
-
-	var results []struct {
-		TotalPoints int `bson:"totalPoints"`
-	}
-	if err = cursor.All(ctx, &results); err != nil {
-		return 0, err
-	}
-
-	if len(results) > 0 {
-		return results[0].TotalPoints, nil
-	}
-	return 0, nil
-}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokeAccessToken memasukkan jti access token ke denylist sampai token kadaluwarsa
+func RevokeAccessToken(db *sql.DB, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, userID, expiresAt)
+	return err
+}
+
+func IsAccessTokenRevoked(db *sql.DB, jti string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&exists)
+	return exists, err
+}
+
+// DeleteExpiredRevokedTokens membersihkan denylist dari token yang memang sudah kadaluwarsa
+func DeleteExpiredRevokedTokens(db *sql.DB) error {
+	_, err := db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	return err
+}
+
+// SetUserTokensRevokedBefore: Semua access token user yang diterbitkan sebelum waktu ini dianggap tidak valid
+func SetUserTokensRevokedBefore(db *sql.DB, userID uuid.UUID, revokedBefore time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO user_token_revocations (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before
+	`, userID, revokedBefore)
+	return err
+}
+
+// DeleteUserTokenRevocationsBefore membersihkan logout-all yang sudah tidak berpengaruh
+// (semua token yang terbit sebelum revoked_before sudah kadaluwarsa)
+func DeleteUserTokenRevocationsBefore(db *sql.DB, before time.Time) error {
+	_, err := db.Exec(`DELETE FROM user_token_revocations WHERE revoked_before < $1`, before)
+	return err
+}
+
+// GetUserTokensRevokedBefore: Return nil jika user belum pernah "logout all"
+func GetUserTokensRevokedBefore(db *sql.DB, userID uuid.UUID) (*time.Time, error) {
+	var t time.Time
+	err := db.QueryRow(`SELECT revoked_before FROM user_token_revocations WHERE user_id = $1`, userID).Scan(&t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
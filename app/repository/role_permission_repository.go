@@ -56,3 +56,27 @@ func RevokePermissionFromRole(db *sql.DB, roleID, permissionID uuid.UUID) error
 	return err
 }
 
+// RoleHasPermission mengecek apakah role memiliki permission dengan nama tertentu
+func RoleHasPermission(db *sql.DB, roleID uuid.UUID, permissionName string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM role_permissions rp
+            JOIN permissions p ON p.id = rp.permission_id
+            WHERE rp.role_id = $1 AND p.name = $2
+        )
+    `, roleID, permissionName).Scan(&exists)
+	return exists, err
+}
+
+// CountRolesWithPermission menghitung jumlah role yang memiliki permission tertentu
+func CountRolesWithPermission(db *sql.DB, permissionName string) (int, error) {
+	var count int
+	err := db.QueryRow(`
+        SELECT COUNT(DISTINCT rp.role_id)
+        FROM role_permissions rp
+        JOIN permissions p ON p.id = rp.permission_id
+        WHERE p.name = $1
+    `, permissionName).Scan(&count)
+	return count, err
+}
@@ -8,24 +8,35 @@ import (
 	"github.com/google/uuid"
 )
 
-func GetAllRoles(db *sql.DB) ([]model.Role, error) {
-	rows, err := db.Query(`
-        SELECT id, name, description, created_at FROM roles ORDER BY name ASC
-    `)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var roles []model.Role
-	for rows.Next() {
+var roleListSpec = ListSpec[model.Role]{
+	Select:      "SELECT id, name, description, created_at",
+	From:        "FROM roles",
+	IDColumn:    "id",
+	Sorts:       map[string]string{"name": "name", "created_at": "created_at"},
+	DefaultSort: []model.SortField{{Field: "name"}},
+	Filters: map[string]ListFilter{
+		"name":         {Column: "name", Kind: FilterILike},
+		"created_from": {Column: "created_at", Kind: FilterFrom},
+		"created_to":   {Column: "created_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.Role, error) {
 		var r model.Role
-		if err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.CreatedAt); err != nil {
-			return nil, err
+		err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.CreatedAt)
+		return r, err
+	},
+	SortValue: func(r model.Role, key string) interface{} {
+		switch key {
+		case "name":
+			return r.Name
+		case "created_at":
+			return r.CreatedAt
 		}
-		roles = append(roles, r)
-	}
-	return roles, nil
+		return r.ID
+	},
+}
+
+func GetAllRoles(db *sql.DB, params model.ListParams) (*model.ListResult[model.Role], error) {
+	return runList(db, roleListSpec, params, nil)
 }
 
 func GetRoleByID(db *sql.DB, id uuid.UUID) (*model.Role, error) {
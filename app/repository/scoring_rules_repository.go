@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"project-uas/app/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const scoringRuleColumns = `id, achievement_type, level, rank_min, rank_max, participation, points, priority,
+	description, is_active, created_at, updated_at`
+
+func scanScoringRule(row interface{ Scan(...interface{}) error }) (model.ScoringRule, error) {
+	var r model.ScoringRule
+	err := row.Scan(&r.ID, &r.AchievementType, &r.Level, &r.RankMin, &r.RankMax, &r.Participation, &r.Points,
+		&r.Priority, &r.Description, &r.IsActive, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+var scoringRuleListSpec = ListSpec[model.ScoringRule]{
+	Select:      "SELECT " + scoringRuleColumns,
+	From:        "FROM scoring_rules",
+	IDColumn:    "id",
+	Sorts:       map[string]string{"achievement_type": "achievement_type", "points": "points", "priority": "priority", "created_at": "created_at"},
+	DefaultSort: []model.SortField{{Field: "achievement_type"}, {Field: "priority", Desc: true}},
+	Filters: map[string]ListFilter{
+		"achievement_type": {Column: "achievement_type", Kind: FilterIn},
+		"is_active":        {Column: "is_active", Kind: FilterEqual},
+	},
+	Scan: func(rows *sql.Rows) (model.ScoringRule, error) {
+		return scanScoringRule(rows)
+	},
+	SortValue: func(r model.ScoringRule, key string) interface{} {
+		switch key {
+		case "achievement_type":
+			return r.AchievementType
+		case "points":
+			return r.Points
+		case "priority":
+			return r.Priority
+		case "created_at":
+			return r.CreatedAt
+		}
+		return r.ID
+	},
+}
+
+func GetAllScoringRules(db *sql.DB, params model.ListParams) (*model.ListResult[model.ScoringRule], error) {
+	return runList(db, scoringRuleListSpec, params, nil)
+}
+
+// GetActiveScoringRules: Semua aturan aktif untuk satu tipe prestasi (dipakai mesin skoring)
+func GetActiveScoringRules(db *sql.DB, achievementType string) ([]model.ScoringRule, error) {
+	rows, err := db.Query(`
+		SELECT `+scoringRuleColumns+` FROM scoring_rules
+		WHERE achievement_type = $1 AND is_active = TRUE
+		ORDER BY priority DESC, created_at
+	`, achievementType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []model.ScoringRule
+	for rows.Next() {
+		r, err := scanScoringRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+func GetScoringRuleByID(db *sql.DB, id uuid.UUID) (*model.ScoringRule, error) {
+	r, err := scanScoringRule(db.QueryRow("SELECT "+scoringRuleColumns+" FROM scoring_rules WHERE id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func CreateScoringRule(db *sql.DB, r *model.ScoringRule) error {
+	r.ID = uuid.New()
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = r.CreatedAt
+	_, err := db.Exec(`
+		INSERT INTO scoring_rules (id, achievement_type, level, rank_min, rank_max, participation, points, priority,
+			description, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, r.ID, r.AchievementType, r.Level, r.RankMin, r.RankMax, r.Participation, r.Points, r.Priority,
+		r.Description, r.IsActive, r.CreatedAt, r.UpdatedAt)
+	return err
+}
+
+func UpdateScoringRule(db *sql.DB, r *model.ScoringRule) error {
+	r.UpdatedAt = time.Now()
+	_, err := db.Exec(`
+		UPDATE scoring_rules
+		SET achievement_type = $1, level = $2, rank_min = $3, rank_max = $4, participation = $5, points = $6,
+			priority = $7, description = $8, is_active = $9, updated_at = $10
+		WHERE id = $11
+	`, r.AchievementType, r.Level, r.RankMin, r.RankMax, r.Participation, r.Points, r.Priority,
+		r.Description, r.IsActive, r.UpdatedAt, r.ID)
+	return err
+}
+
+func DeleteScoringRule(db *sql.DB, id uuid.UUID) error {
+	_, err := db.Exec("DELETE FROM scoring_rules WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Kelas advisory lock (pg_advisory_xact_lock dua argumen) untuk key storage lampiran
+const storageKeyLockClass = 72010602
+
+// WithStorageKeyLock menjalankan fn sambil memegang advisory lock Postgres untuk key storage.
+// shared=true untuk upload (boleh bersamaan), false untuk penghapusan file. Lock dilepas saat transaksi selesai.
+func WithStorageKeyLock(db *sql.DB, key string, shared bool, fn func() error) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lock := `SELECT pg_advisory_xact_lock($1, hashtext($2))`
+	if shared {
+		lock = `SELECT pg_advisory_xact_lock_shared($1, hashtext($2))`
+	}
+	if _, err := tx.ExecContext(ctx, lock, storageKeyLockClass, key); err != nil {
+		return err
+	}
+	return fn()
+}
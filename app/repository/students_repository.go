@@ -8,29 +8,59 @@ import (
 	"github.com/google/uuid"
 )
 
-func GetAllStudents(db *sql.DB) ([]model.Student, error) {
-	rows, err := db.Query(`
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at 
-        FROM students ORDER BY created_at DESC
-    `)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var students []model.Student
-	for rows.Next() {
+var studentListSpec = ListSpec[model.Student]{
+	Select:   "SELECT s.id, s.user_id, s.student_id, s.program_study, s.academic_year, s.advisor_id, s.created_at",
+	From:     "FROM students s LEFT JOIN lecturers l ON l.id = s.advisor_id",
+	IDColumn: "s.id",
+	Sorts: map[string]string{
+		"student_id": "s.student_id", "program_study": "s.program_study",
+		"academic_year": "s.academic_year", "created_at": "s.created_at",
+	},
+	DefaultSort: []model.SortField{{Field: "created_at", Desc: true}},
+	Filters: map[string]ListFilter{
+		"student_id":    {Column: "s.student_id", Kind: FilterILike},
+		"program_study": {Column: "s.program_study", Kind: FilterEqual},
+		"academic_year": {Column: "s.academic_year", Kind: FilterEqual},
+		"advisor_id":    {Column: "s.advisor_id", Kind: FilterEqual},
+		"department":    {Column: "l.department", Kind: FilterEqual}, // Departemen dosen wali
+		"created_from":  {Column: "s.created_at", Kind: FilterFrom},
+		"created_to":    {Column: "s.created_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.Student, error) {
 		var s model.Student
-		if err := rows.Scan(
-			&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy,
-			&s.AcademicYear, &s.AdvisorID, &s.CreatedAt,
-		); err != nil {
-			return nil, err
+		err := rows.Scan(&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy, &s.AcademicYear, &s.AdvisorID, &s.CreatedAt)
+		return s, err
+	},
+	SortValue: func(s model.Student, key string) interface{} {
+		switch key {
+		case "student_id":
+			return s.StudentID
+		case "program_study":
+			return s.ProgramStudy
+		case "academic_year":
+			return s.AcademicYear
+		case "created_at":
+			return s.CreatedAt
 		}
-		students = append(students, s)
-	}
+		return s.ID
+	},
+}
 
-	return students, nil
+// StudentListScope: Batas daftar mahasiswa sesuai aktor (field nil = tanpa batas)
+type StudentListScope struct {
+	StudentID *uuid.UUID // Mahasiswa: hanya dirinya sendiri (PK students)
+	AdvisorID *uuid.UUID // Dosen wali: hanya mahasiswa bimbingannya (PK lecturers)
+}
+
+func GetAllStudents(db *sql.DB, params model.ListParams, scope StudentListScope) (*model.ListResult[model.Student], error) {
+	where := &sqlWhere{}
+	if scope.StudentID != nil {
+		where.add("s.id = ?", *scope.StudentID)
+	}
+	if scope.AdvisorID != nil {
+		where.add("s.advisor_id = ?", *scope.AdvisorID)
+	}
+	return runList(db, studentListSpec, params, where)
 }
 
 // GetStudentByID (Berdasarkan UserID sesuai endpoint param)
@@ -86,14 +116,14 @@ func UpdateAdvisor(db *sql.DB, studentID uuid.UUID, advisorID *uuid.UUID) error
 	return err
 }
 
-// GetAchievementsByStudentID: Mengambil list referensi prestasi
+// GetAchievementsByStudentID: Mengambil list referensi prestasi (tanpa yang ada di trash)
 // FIX: Menggunakan tabel achievement_references, bukan achievements
 func GetAchievementsByStudentID(db *sql.DB, studentID uuid.UUID) ([]model.AchievementReference, error) {
 	rows, err := db.Query(`
         SELECT id, student_id, mongo_achievement_id, status, submitted_at, 
                verified_at, verified_by, rejection_note, created_at, updated_at
         FROM achievement_references
-        WHERE student_id = $1
+        WHERE student_id = $1 AND deleted_at IS NULL
         ORDER BY created_at DESC
     `, studentID)
 	if err != nil {
@@ -113,4 +143,21 @@ func GetAchievementsByStudentID(db *sql.DB, studentID uuid.UUID) ([]model.Achiev
 		list = append(list, r)
 	}
 	return list, nil
-}
\ No newline at end of file
+}
+// GetStudentByPK mengambil data student berdasarkan Primary Key tabel students (bukan UserID)
+func GetStudentByPK(db *sql.DB, id uuid.UUID) (*model.Student, error) {
+	var s model.Student
+	row := db.QueryRow(`
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at 
+        FROM students WHERE id = $1
+    `, id)
+
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy,
+		&s.AcademicYear, &s.AdvisorID, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
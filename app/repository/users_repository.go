@@ -49,25 +49,48 @@ func GetPermissionNamesByRoleID(db *sql.DB, roleID uuid.UUID) ([]string, error)
 
 // --- FUNGSI CRUD USER (FR-009) ---
 
-func GetAllUsers(db *sql.DB) ([]model.User, error) {
-	rows, err := db.Query(`
-		SELECT id, username, email, full_name, role_id, is_active, created_at, updated_at
-		FROM users ORDER BY full_name ASC
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var users []model.User
-	for rows.Next() {
+var userListSpec = ListSpec[model.User]{
+	Select:   "SELECT id, username, email, full_name, role_id, is_active, created_at, updated_at",
+	From:     "FROM users",
+	IDColumn: "id",
+	Sorts: map[string]string{
+		"full_name": "full_name", "username": "username", "email": "email",
+		"created_at": "created_at", "updated_at": "updated_at",
+	},
+	DefaultSort: []model.SortField{{Field: "full_name"}},
+	Filters: map[string]ListFilter{
+		"role_id":      {Column: "role_id", Kind: FilterEqual},
+		"is_active":    {Column: "is_active", Kind: FilterEqual},
+		"full_name":    {Column: "full_name", Kind: FilterILike},
+		"username":     {Column: "username", Kind: FilterILike},
+		"email":        {Column: "email", Kind: FilterILike},
+		"created_from": {Column: "created_at", Kind: FilterFrom},
+		"created_to":   {Column: "created_at", Kind: FilterTo},
+	},
+	Scan: func(rows *sql.Rows) (model.User, error) {
 		var u model.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt); err != nil {
-			return nil, err
+		err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
+		return u, err
+	},
+	SortValue: func(u model.User, key string) interface{} {
+		switch key {
+		case "full_name":
+			return u.FullName
+		case "username":
+			return u.Username
+		case "email":
+			return u.Email
+		case "created_at":
+			return u.CreatedAt
+		case "updated_at":
+			return u.UpdatedAt
 		}
-		users = append(users, u)
-	}
-	return users, nil
+		return u.ID
+	},
+}
+
+func GetAllUsers(db *sql.DB, params model.ListParams) (*model.ListResult[model.User], error) {
+	return runList(db, userListSpec, params, nil)
 }
 
 func GetUserByID(db *sql.DB, id uuid.UUID) (*model.User, error) {
@@ -0,0 +1,158 @@
+package response
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"project-uas/helper"
+
+	"github.com/lib/pq"
+)
+
+// Kode error di field "code" body error (stabil, dipakai frontend; message bisa berubah)
+const (
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeUnauthorized    = "UNAUTHORIZED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeConflict        = "CONFLICT"
+	CodeValidation      = "VALIDATION_FAILED"
+	CodePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+	CodeInternal        = "INTERNAL_ERROR"
+)
+
+// Error: Error aplikasi yang dipetakan ErrorHandler ke satu bentuk JSON.
+// Cause hanya di-log (tidak pernah dikirim ke client).
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []helper.FieldError    // Error per field (422)
+	Details map[string]interface{} // Info tambahan, mis. current_status
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// With menambah info ke "details" (mengembalikan e agar bisa dirangkai)
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+func newError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return newError(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return newError(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return newError(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return newError(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return newError(http.StatusConflict, CodeConflict, message)
+}
+
+func PayloadTooLarge(message string) *Error {
+	return newError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message)
+}
+
+// Validation: 422 dengan daftar field yang tidak valid
+func Validation(fields []helper.FieldError) *Error {
+	e := newError(http.StatusUnprocessableEntity, CodeValidation, "Validasi gagal")
+	e.Fields = fields
+	return e
+}
+
+// Internal: 500, cause di-log bersama request ID
+func Internal(message string, cause error) *Error {
+	e := newError(http.StatusInternalServerError, CodeInternal, message)
+	e.Cause = cause
+	return e
+}
+
+// Kode error PostgreSQL yang diterjemahkan (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqNotNullViolation    = "23502"
+	pqCheckViolation      = "23514"
+	pqStringTooLong       = "22001"
+	pqInvalidText         = "22P02"
+)
+
+// FromDB menerjemahkan error database: sql.ErrNoRows -> 404, pelanggaran constraint -> 409 / 422,
+// selain itu 500 dengan message sebagai pesan untuk client.
+func FromDB(err error, message string) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return NotFound("Data tidak ditemukan")
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return Internal(message, err)
+	}
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		e := Conflict("Data sudah ada (duplikat)")
+		if pqErr.Constraint != "" {
+			e.With("constraint", pqErr.Constraint)
+		}
+		e.Cause = err
+		return e
+	case pqForeignKeyViolation:
+		e := Conflict("Data terkait tidak ditemukan atau masih dipakai data lain")
+		if pqErr.Constraint != "" {
+			e.With("constraint", pqErr.Constraint)
+		}
+		e.Cause = err
+		return e
+	case pqNotNullViolation:
+		return withCause(Validation([]helper.FieldError{{Field: pqErr.Column, Message: "wajib diisi"}}), err)
+	case pqStringTooLong:
+		return withCause(Validation([]helper.FieldError{{Field: pqErr.Column, Message: "terlalu panjang"}}), err)
+	case pqCheckViolation:
+		return withCause(BadRequest("Data tidak memenuhi aturan ("+pqErr.Constraint+")"), err)
+	case pqInvalidText:
+		return withCause(BadRequest("Format data tidak valid"), err)
+	}
+	return Internal(message, err)
+}
+
+func withCause(e *Error, cause error) *Error {
+	e.Cause = cause
+	return e
+}
+
+// IsUniqueViolation: true jika err adalah pelanggaran unique constraint (23505).
+// Dipakai handler yang ingin pesan 409 lebih spesifik daripada pesan bawaan FromDB.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
@@ -0,0 +1,69 @@
+package response
+
+import (
+	"errors"
+	"log"
+	"project-uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// ErrorBody: Bentuk JSON semua response error
+type ErrorBody struct {
+	Success   bool                   `json:"success"` // selalu false
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Errors    []helper.FieldError    `json:"errors,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ErrorHandler dipasang di fiber.Config: handler / middleware cukup `return response.NotFound(...)`.
+// Error selain *Error (mis. *fiber.Error dari router, error mentah) tetap dikirim dalam bentuk yang sama.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	appErr := toError(err)
+	requestID, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", requestID, c.Method(), c.Path(), appErr)
+	}
+
+	return c.Status(appErr.Status).JSON(ErrorBody{
+		Success:   false,
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		RequestID: requestID,
+		Errors:    appErr.Fields,
+		Details:   appErr.Details,
+	})
+}
+
+func toError(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusBadRequest:
+			return BadRequest(fiberErr.Message)
+		case fiber.StatusUnauthorized:
+			return Unauthorized(fiberErr.Message)
+		case fiber.StatusForbidden:
+			return Forbidden(fiberErr.Message)
+		case fiber.StatusNotFound:
+			return NotFound(fiberErr.Message)
+		case fiber.StatusConflict:
+			return Conflict(fiberErr.Message)
+		case fiber.StatusRequestEntityTooLarge:
+			return PayloadTooLarge(fiberErr.Message)
+		}
+		if fiberErr.Code < fiber.StatusInternalServerError {
+			return newError(fiberErr.Code, CodeBadRequest, fiberErr.Message)
+		}
+	}
+	return Internal("Terjadi kesalahan pada server", err)
+}
@@ -0,0 +1,85 @@
+package response
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/lib/pq"
+)
+
+func TestFromDB(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"no rows", sql.ErrNoRows, 404, CodeNotFound},
+		{"unique", &pq.Error{Code: "23505", Constraint: "users_email_key"}, 409, CodeConflict},
+		{"foreign key", &pq.Error{Code: "23503"}, 409, CodeConflict},
+		{"not null", &pq.Error{Code: "23502", Column: "name"}, 422, CodeValidation},
+		{"invalid text", &pq.Error{Code: "22P02"}, 400, CodeBadRequest},
+		{"wrapped unique", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), 409, CodeConflict},
+		{"other", errors.New("connection refused"), 500, CodeInternal},
+	}
+	for _, tc := range cases {
+		got := FromDB(tc.err, "Gagal")
+		if got.Status != tc.status || got.Code != tc.code {
+			t.Errorf("%s: got %d %s, want %d %s", tc.name, got.Status, got.Code, tc.status, tc.code)
+		}
+	}
+
+	if e := FromDB(&pq.Error{Code: "23505", Constraint: "users_email_key"}, ""); e.Details["constraint"] != "users_email_key" {
+		t.Errorf("constraint tidak masuk details: %v", e.Details)
+	}
+	if !IsUniqueViolation(&pq.Error{Code: "23505"}) || IsUniqueViolation(&pq.Error{Code: "23503"}) {
+		t.Error("IsUniqueViolation salah mendeteksi kode")
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(requestid.New())
+	app.Get("/conflict", func(c *fiber.Ctx) error {
+		return Conflict("Status tidak valid").With("current_status", "draft")
+	})
+	app.Get("/raw", func(c *fiber.Ctx) error {
+		return errors.New("rahasia internal")
+	})
+
+	cases := []struct {
+		path    string
+		status  int
+		code    string
+		message string
+	}{
+		{"/conflict", 409, CodeConflict, "Status tidak valid"},
+		{"/raw", 500, CodeInternal, "Terjadi kesalahan pada server"},
+		{"/tidak-ada", 404, CodeNotFound, "Cannot GET /tidak-ada"},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body ErrorBody
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status || body.Success || body.Code != tc.code || body.Message != tc.message {
+			t.Errorf("%s: got %d %+v", tc.path, resp.StatusCode, body)
+		}
+		if body.RequestID == "" || body.RequestID != resp.Header.Get(fiber.HeaderXRequestID) {
+			t.Errorf("%s: request_id %q tidak sama dengan header", tc.path, body.RequestID)
+		}
+		if tc.path == "/conflict" && body.Details["current_status"] != "draft" {
+			t.Errorf("details hilang: %v", body.Details)
+		}
+	}
+}
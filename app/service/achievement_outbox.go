@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/database"
+	"sort"
+	"time"
+)
+
+// Konsistensi Postgres (achievement_references) <-> Mongo (achievements):
+//   - Create : saga. Dokumen Mongo ditulis dulu; jika reference gagal disimpan, dokumen dihapus
+//              (kompensasi). Jika kompensasi gagal, penghapusan diantrekan di outbox.
+//   - Delete / Restore : perubahan reference + baris outbox ditulis dalam satu transaksi Postgres,
+//              lalu worker outbox menjalankan operasi Mongo dengan retry (backoff).
+//   - Sisanya (crash di tengah saga, data lama) ditangani perintah `reconcile`.
+
+const (
+	outboxBatchSize  = 50
+	outboxMaxBackoff = time.Hour
+
+	// Dokumen Mongo yang lebih muda dari ini tidak dianggap orphan (bisa jadi create masih berjalan)
+	ReconcileOrphanGrace = 10 * time.Minute
+)
+
+// StartAchievementOutboxWorker menjalankan worker outbox sampai ctx dibatalkan
+func StartAchievementOutboxWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := ProcessAchievementOutbox(); err != nil {
+				log.Println("outbox prestasi:", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ProcessAchievementOutbox menjalankan satu batch outbox yang jatuh tempo
+func ProcessAchievementOutbox() (int, error) {
+	return repository.ProcessDueAchievementOutbox(database.DB, outboxBatchSize, handleAchievementOutbox, outboxBackoff)
+}
+
+func handleAchievementOutbox(o model.AchievementOutbox) error {
+	switch o.Operation {
+	case model.OutboxMongoDelete:
+		return repository.DeleteAchievementMongo(database.MongoDB, o.MongoAchievementID)
+	case model.OutboxMongoSoftDelete, model.OutboxMongoRestore:
+		// Delete lalu restore cepat bisa diproses tidak berurutan jika salah satu di-retry,
+		// jadi operasi hanya dijalankan jika masih sesuai status reference saat ini.
+		if o.AchievementID == nil {
+			return nil
+		}
+		status, err := repository.GetAchievementReferenceStatus(database.DB, *o.AchievementID)
+		if err == sql.ErrNoRows {
+			return nil // Sudah di-purge
+		} else if err != nil {
+			return err
+		}
+		deleted := status == model.StatusDeleted
+		if o.Operation == model.OutboxMongoSoftDelete && deleted {
+			return repository.SoftDeleteAchievementMongo(database.MongoDB, o.MongoAchievementID, o.CreatedAt)
+		}
+		if o.Operation == model.OutboxMongoRestore && !deleted {
+			return repository.RestoreAchievementMongo(database.MongoDB, o.MongoAchievementID)
+		}
+		return nil
+	case model.OutboxMongoScoring:
+		// Nilai absolut (bukan increment), jadi aman diulang walaupun sudah diterapkan langsung oleh handler
+		var p model.OutboxScoringPayload
+		if err := json.Unmarshal(o.Payload, &p); err != nil {
+			return fmt.Errorf("payload outbox tidak valid: %w", err)
+		}
+		return repository.UpdateAchievementScoring(database.MongoDB, o.MongoAchievementID, p.Points, p.Scoring)
+	default:
+		return fmt.Errorf("operasi outbox tidak dikenal: %s", o.Operation)
+	}
+}
+
+// outboxBackoff: 10s, 20s, 40s, ... maksimal 1 jam
+func outboxBackoff(attempts int) time.Duration {
+	d := 10 * time.Second
+	for i := 1; i < attempts && d < outboxMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > outboxMaxBackoff {
+		d = outboxMaxBackoff
+	}
+	return d
+}
+
+// compensateMongoInsert menghapus dokumen Mongo yang reference-nya gagal disimpan
+func compensateMongoInsert(mongoID string) {
+	err := repository.DeleteAchievementMongo(database.MongoDB, mongoID)
+	if err == nil {
+		return
+	}
+	log.Printf("kompensasi mongo %s gagal, diantrekan ke outbox: %v", mongoID, err)
+	if err := repository.EnqueueAchievementOutbox(database.DB, nil, mongoID, model.OutboxMongoDelete); err != nil {
+		// Postgres juga bermasalah: dokumen akan terdeteksi sebagai orphan oleh `reconcile`
+		log.Printf("gagal mengantrekan kompensasi mongo %s: %v", mongoID, err)
+	}
+}
+
+// ReconcileAchievements membandingkan reference vs dokumen Mongo. Jika fix = true:
+// dokumen orphan dihapus dan reference aktif tanpa dokumen di-soft delete.
+func ReconcileAchievements(fix bool) (*model.ReconcileReport, error) {
+	refs, err := repository.GetAllAchievementReferencesForReconcile(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("baca achievement_references: %w", err)
+	}
+	docs, err := repository.ListAchievementMongoIDs(database.MongoDB)
+	if err != nil {
+		return nil, fmt.Errorf("baca collection achievements: %w", err)
+	}
+
+	report := FindAchievementStoreMismatches(refs, docs, time.Now().Add(-ReconcileOrphanGrace))
+	if !fix {
+		return report, nil
+	}
+
+	for _, mongoID := range report.Orphans {
+		if err := repository.DeleteAchievementMongo(database.MongoDB, mongoID); err != nil {
+			return report, fmt.Errorf("hapus orphan %s: %w", mongoID, err)
+		}
+	}
+	note := "reconcile: dokumen Mongo tidak ditemukan"
+	for i := range report.Dangling {
+		ref := &report.Dangling[i]
+		oldStatus := ref.Status
+		event := &model.AchievementStatusEvent{OldStatus: &oldStatus, ActorRole: "system", Note: &note}
+		err := repository.DeleteAchievementReference(database.DB, ref, event)
+		if errors.Is(err, repository.ErrAchievementStatusChanged) {
+			continue // Reference diubah sejak dibaca, dicek ulang di reconcile berikutnya
+		}
+		if err != nil {
+			return report, fmt.Errorf("soft delete reference %s: %w", ref.ID, err)
+		}
+	}
+	report.Fixed = true
+	return report, nil
+}
+
+// FindAchievementStoreMismatches: Orphan = dokumen Mongo (dibuat sebelum orphanCutoff) yang tidak dirujuk
+// reference mana pun (termasuk yang sudah dihapus). Dangling = reference aktif yang dokumennya tidak ada.
+func FindAchievementStoreMismatches(refs []model.AchievementReference, docs map[string]time.Time, orphanCutoff time.Time) *model.ReconcileReport {
+	report := &model.ReconcileReport{
+		References: len(refs), MongoDocuments: len(docs),
+		Orphans: []string{}, Dangling: []model.AchievementReference{},
+	}
+
+	referenced := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		referenced[ref.MongoAchievementID] = struct{}{}
+		if ref.Status == model.StatusDeleted {
+			continue
+		}
+		if _, ok := docs[ref.MongoAchievementID]; !ok {
+			report.Dangling = append(report.Dangling, ref)
+		}
+	}
+
+	for mongoID, createdAt := range docs {
+		if _, ok := referenced[mongoID]; ok {
+			continue
+		}
+		if createdAt.Before(orphanCutoff) {
+			report.Orphans = append(report.Orphans, mongoID)
+		}
+	}
+	sort.Strings(report.Orphans)
+	return report
+}
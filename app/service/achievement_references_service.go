@@ -1,66 +1,151 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"log"
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
+	"project-uas/helper"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/google/uuid"
 )
 
 // GET /api/v1/achievements (Filtered by Role)
 // ListAchievements godoc
 // @Summary      Lihat Daftar Prestasi
-// @Description  Menampilkan daftar prestasi berdasarkan role (Mahasiswa: milik sendiri, Dosen: milik bimbingan, Admin: semua)
+// @Description  Menampilkan daftar prestasi berdasarkan role (Mahasiswa: milik sendiri, Dosen: milik bimbingan, Admin: semua) beserta judul, tipe, poin, tag, dan jumlah lampiran
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.AchievementReference}
+// @Param        page            query  int     false  "Halaman (default 1)"
+// @Param        limit           query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after           query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort            query  string  false  "created_at, updated_at, status (prefix - untuk desc)"
+// @Param        status          query  string  false  "Filter status (pisahkan koma)"
+// @Param        program_study   query  string  false  "Filter program studi mahasiswa"
+// @Param        academic_year   query  string  false  "Filter angkatan mahasiswa"
+// @Param        department      query  string  false  "Filter departemen dosen wali"
+// @Param        achievement_type query string  false  "Filter tipe prestasi (Mongo)"
+// @Param        tag             query  string  false  "Filter tag (Mongo)"
+// @Param        created_from    query  string  false  "Tanggal dibuat (YYYY-MM-DD), juga submitted_/verified_"
+// @Param        created_to      query  string  false  "Tanggal dibuat sampai (YYYY-MM-DD)"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementListItem,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /achievements [get]
 func ListAchievements(c *fiber.Ctx) error {
-	// Ambil UserID dan RoleID dari Middleware Locals
-	userIDStr := c.Locals("user_id").(string)
-	userID, _ := uuid.Parse(userIDStr)
-	
-	// Cek apakah user adalah Student, Dosen, atau Admin
-	// Cara terbaik: Cek di tabel Users / Lecturers / Students
-	// Disini kita asumsikan check sederhana via repository helpers
-	
-	var refs []model.AchievementReference
-	var err error
-
-	// 1. Cek apakah dia Student?
-	student, errStudent := repository.GetStudentByID(database.DB, userID)
-	if errStudent == nil {
-		// Dia Student: Tampilkan miliknya saja
-		refs, err = repository.GetAchievementReferencesByStudentID(database.DB, student.ID)
-	} else {
-		// 2. Cek apakah dia Lecturer?
-		lecturer, errLecturer := repository.GetLecturerByID(database.DB, userID)
-		if errLecturer == nil {
-			// Dia Lecturer: Tampilkan milik mahasiswa bimbingannya
-			refs, err = repository.GetAchievementReferencesByAdvisorID(database.DB, lecturer.ID)
-		} else {
-			// 3. Asumsi Admin: Tampilkan Semua
-			refs, err = repository.GetAllAchievementReferences(database.DB)
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+
+	// Scope data berdasarkan aktor yang sudah di-resolve oleh AuthProtected
+	scope, ok := achievementScope(currentActor(c))
+	if !ok {
+		// Aktor lain (role custom / profil belum dibuat): tidak ada akses data
+		return listResponse(c, params, &model.ListResult[model.AchievementListItem]{Items: []model.AchievementListItem{}})
+	}
+	return listAchievementItems(c, scope, params)
+}
+
+// listAchievementItems: List reference sesuai scope, filter konten (achievement_type, tag) di Mongo,
+// lalu digabung dengan ringkasan konten Mongo
+func listAchievementItems(c *fiber.Ctx, scope repository.AchievementListScope, params model.ListParams) error {
+	if status, ok := params.Filters["status"]; ok {
+		for _, st := range strings.Split(status, ",") {
+			if !model.AchievementStatus(strings.TrimSpace(st)).Valid() {
+				return response.Validation([]helper.FieldError{{Field: "status", Message: "status tidak dikenal: " + st}})
+			}
 		}
 	}
+	if err := applyAchievementContentFilter(&scope, params); err != nil {
+		return response.Internal("Gagal memfilter konten prestasi", err)
+	}
+
+	refs, err := repository.ListAchievementReferences(database.DB, scope, params)
+	if err != nil {
+		return listError(err, "Gagal mengambil data")
+	}
+	items, err := enrichAchievementReferences(refs.Items)
+	if err != nil {
+		return response.Internal("Gagal mengambil detail prestasi", err)
+	}
+	return listResponse(c, params, &model.ListResult[model.AchievementListItem]{Items: items, Total: refs.Total, NextCursor: refs.NextCursor})
+}
+
+// applyAchievementContentFilter: Filter achievement_type / tag dicari dulu di Mongo, hasilnya
+// membatasi query Postgres sehingga total & pagination tetap benar
+func applyAchievementContentFilter(scope *repository.AchievementListScope, params model.ListParams) error {
+	filter := repository.AchievementContentFilter{
+		AchievementType: params.Filters["achievement_type"],
+		Tag:             params.Filters["tag"],
+		StudentID:       scope.StudentID,
+	}
+	if filter.AchievementType == "" && filter.Tag == "" {
+		return nil
+	}
+	ids, err := repository.FindAchievementMongoIDs(database.MongoDB, filter)
+	if err != nil {
+		return err
+	}
+	scope.MongoIDs = ids
+	return nil
+}
 
+// enrichAchievementReferences menggabungkan reference dengan konten Mongo (satu query $in)
+func enrichAchievementReferences(refs []model.AchievementReference) ([]model.AchievementListItem, error) {
+	mongoIDs := make([]string, len(refs))
+	for i, ref := range refs {
+		mongoIDs[i] = ref.MongoAchievementID
+	}
+	docs, err := repository.GetAchievementsMongoByIDs(database.MongoDB, mongoIDs)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data"})
+		return nil, err
+	}
+
+	items := make([]model.AchievementListItem, len(refs))
+	for i, ref := range refs {
+		items[i] = model.AchievementListItem{AchievementReference: ref, Tags: []string{}}
+		if doc, ok := docs[ref.MongoAchievementID]; ok {
+			items[i].Title = doc.Title
+			items[i].AchievementType = doc.AchievementType
+			items[i].Points = doc.Points
+			items[i].AttachmentCount = len(doc.Attachments)
+			if doc.Tags != nil {
+				items[i].Tags = doc.Tags
+			}
+		}
+	}
+	return items, nil
+}
+
+// achievementScope: Admin semua, mahasiswa miliknya, dosen wali milik bimbingannya.
+// ok = false jika aktor tidak berhak melihat data prestasi sama sekali.
+func achievementScope(actor *model.Actor) (repository.AchievementListScope, bool) {
+	switch {
+	case actor.IsAdmin():
+		return repository.AchievementListScope{}, true
+	case actor != nil && actor.Kind == model.ActorStudent && actor.StudentID != nil:
+		return repository.AchievementListScope{StudentID: actor.StudentID}, true
+	case actor != nil && actor.Kind == model.ActorLecturer && actor.LecturerID != nil:
+		return repository.AchievementListScope{AdvisorID: actor.LecturerID}, true
 	}
-	return c.JSON(fiber.Map{"success": true, "data": refs})
+	return repository.AchievementListScope{}, false
 }
 
 // GET /api/v1/achievements/:id
 // GetAchievementDetail godoc
 // @Summary      Lihat Detail Prestasi
 // @Description  Mendapatkan detail lengkap prestasi (gabungan data Postgres & MongoDB) berdasarkan ID
+// @Description  Lampiran gambar / PDF yang sudah diproses menyertakan preview_url (signed URL thumbnail, 5 menit).
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Produce      json
@@ -72,9 +157,13 @@ func GetAchievementDetail(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Not found"})
+		return response.NotFound("Not found")
 	}
 	detail, _ := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if detail != nil {
+		// Thumbnail / halaman pertama lampiran bisa langsung ditampilkan tanpa mengunduh file
+		fillAttachmentPreviewURLs(c.Context(), detail)
+	}
 	return c.JSON(fiber.Map{"success": true, "data": fiber.Map{"reference": ref, "detail": detail}})
 }
 
@@ -88,38 +177,66 @@ func GetAchievementDetail(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        request body model.CreateAchievementRequest true "Data Prestasi"
 // @Success      201  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /achievements [post]
 func CreateAchievement(c *fiber.Ctx) error {
 	var req model.CreateAchievementRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid body"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	// StudentID diambil dari aktor yang login, bukan dari body
+	actor := currentActor(c)
+	if actor == nil || actor.Kind != model.ActorStudent || actor.StudentID == nil {
+		return response.Forbidden("Hanya mahasiswa yang bisa membuat prestasi")
+	}
+	studentID := *actor.StudentID
+
+	// Tipe harus terdaftar & aktif, details harus sesuai schema tipe
+	fieldErrs, err := validateAchievementDetails(req.AchievementType, req.Details, true)
+	if err != nil {
+		return response.Internal("Gagal memuat tipe prestasi", err)
+	}
+	if len(fieldErrs) > 0 {
+		return response.Validation(fieldErrs)
+	}
+
+	// Poin dihitung server dari aturan skoring, bukan diisi mahasiswa
+	scoring, err := scoreAchievement(req.AchievementType, req.Details)
+	if err != nil {
+		return response.Internal("Gagal menghitung poin", err)
 	}
 
-	// Logic Auth: Pastikan student_id di body sesuai dengan user yang login (Optional security check)
-	
 	// 1. Simpan ke Mongo
 	mongoData := model.Achievement{
-		StudentID: req.StudentID, AchievementType: req.AchievementType,
+		StudentID: studentID, AchievementType: req.AchievementType,
 		Title: req.Title, Description: req.Description, Details: req.Details,
-		Tags: req.Tags, Points: req.Points, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		Tags: req.Tags, Points: effectivePoints(scoring), Scoring: &scoring,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
 	}
 	mongoID, err := repository.InsertAchievementMongo(database.MongoDB, mongoData)
-	if err != nil { return c.Status(500).JSON(fiber.Map{"success": false, "message": "Mongo Error"})}
+	if err != nil { return response.Internal("Mongo Error", err)}
 
 	// 2. Simpan ke Postgres (Status Draft)
 	ref := &model.AchievementReference{
-		StudentID: req.StudentID, MongoAchievementID: mongoID, Status: model.StatusDraft,
+		StudentID: studentID, MongoAchievementID: mongoID, Status: model.StatusDraft,
+	}
+	if err := repository.CreateAchievementReference(database.DB, ref, newStatusEvent(c, nil, nil)); err != nil {
+		// Kompensasi saga: jangan tinggalkan dokumen Mongo tanpa reference
+		compensateMongoInsert(mongoID)
+		return response.Internal("Postgres Error", err)}
+	if _, err := recordAchievementVersion(c, ref, model.VersionActionCreate); err != nil {
+		return err
 	}
-	if err := repository.CreateAchievementReference(database.DB, ref); err != nil {
-		return c.Status(500).JSON(fiber.Map{"success": false, "message": "Postgres Error"})}
 
 	return c.Status(201).JSON(fiber.Map{"success": true, "message": "Draft created", "data": ref})
 }
 
 // PUT /api/v1/achievements/:id (Update Draft)
 // UpdateAchievement godoc
-// @Summary      Edit Prestasi (Hanya Draft)
-// @Description  Mahasiswa mengedit data prestasi yang masih berstatus draft
+// @Summary      Edit Prestasi (Draft / Revisi)
+// @Description  Mahasiswa mengedit prestasi berstatus draft, atau memperbaiki prestasi rejected / changes_requested (status menjadi revised)
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Accept       json
@@ -127,29 +244,78 @@ func CreateAchievement(c *fiber.Ctx) error {
 // @Param        id   path      string true "Achievement ID"
 // @Param        request body model.UpdateAchievementRequest true "Data Update"
 // @Success      200  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /achievements/{id} [put]
 func UpdateAchievement(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	var req model.UpdateAchievementRequest
-	if err := c.BodyParser(&req); err != nil { return c.SendStatus(400) }
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
 
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
+
+	next, err := NextAchievementStatus(ActionEdit, ref.Status, actorKind(c))
+	if err != nil {
+		return workflowError(ref, err)
+	}
+
+	// Details divalidasi terhadap tipe baru (jika diganti) atau tipe dokumen saat ini
+	current, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return response.Internal("Gagal mengambil konten prestasi", err)
+	}
+	achievementType := current.AchievementType
+	changingType := req.AchievementType != "" && req.AchievementType != current.AchievementType
+	if changingType {
+		achievementType = req.AchievementType
+	}
+	fieldErrs, err := validateAchievementDetails(achievementType, req.Details, changingType)
+	if err != nil {
+		return response.Internal("Gagal memuat tipe prestasi", err)
+	}
+	if len(fieldErrs) > 0 {
+		return response.Validation(fieldErrs)
+	}
+
+	// Hitung ulang poin dari details baru (penyesuaian dosen sebelumnya tidak berlaku lagi)
+	scoring, err := scoreAchievement(achievementType, req.Details)
+	if err != nil {
+		return response.Internal("Gagal menghitung poin", err)
+	}
 
-	if ref.Status != model.StatusDraft {
-		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Hanya draft yang bisa diedit"})
+	if err := ensureBaselineVersion(ref); err != nil {
+		return response.Internal("Gagal menyiapkan versi", err)
 	}
 
 	// Update Mongo
 	updateData := map[string]interface{}{
 		"title": req.Title, "description": req.Description, 
-		"details": req.Details, "tags": req.Tags, "points": req.Points,
+		"details": req.Details, "tags": req.Tags,
+		"points": effectivePoints(scoring), "scoring": scoring,
+	}
+	if changingType {
+		updateData["achievementType"] = achievementType
 	}
 	if err := repository.UpdateAchievementMongo(database.MongoDB, ref.MongoAchievementID, updateData); err != nil {
-		return c.SendStatus(500)
+		return response.Internal("Gagal mengupdate konten prestasi", err)
+	}
+	version, err := recordAchievementVersion(c, ref, model.VersionActionUpdate)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Prestasi updated"})
+	// Edit setelah rejected / changes_requested -> status revised
+	if err := applyEditTransition(c, ref, next); err != nil {
+		return err
+	}
+
+	data := fiber.Map{"status": ref.Status, "version": version.Version}
+	return c.JSON(fiber.Map{"success": true, "message": "Prestasi updated", "data": data})
 }
 
 // DELETE /api/v1/achievements/:id
@@ -160,31 +326,25 @@ func UpdateAchievement(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Param        id   path      string true "Achievement ID"
 // @Success      200  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
 // @Router       /achievements/{id} [delete]
 func DeleteAchievement(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
-
-	// Validasi: Hanya draft yang bisa dihapus (tetap dipertahankan)
-	if ref.Status != model.StatusDraft {
-		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Hanya draft yang bisa dihapus"})
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
 	}
 
-	// 1. JANGAN Hapus data di Mongo jika ingin bisa di-restore nantinya.
-	// Jika tetap ingin menghapus mongo secara permanen, biarkan baris ini.
-	// repository.DeleteAchievementMongo(database.MongoDB, ref.MongoAchievementID) 
-    
-    // ATAU: Implementasikan Soft Delete juga di Mongo (menambah field deletedAt di Mongo).
-    // Untuk sekarang, kita anggap Mongo dibiarkan saja (data sampah) atau dihapus permanen.
-    // Jika Anda menghapus permanen Mongo:
-    repository.DeleteAchievementMongo(database.MongoDB, ref.MongoAchievementID)
+	// Validasi: Hanya draft yang bisa dihapus (lihat achievement_workflow.go)
+	if _, err := NextAchievementStatus(ActionDelete, ref.Status, actorKind(c)); err != nil {
+		return workflowError(ref, err)
+	}
 
-	// 2. Soft Delete di Postgres (Fungsi repository sudah diubah jadi UPDATE)
-	err = repository.DeleteAchievementReference(database.DB, id)
-    if err != nil {
-        return c.Status(500).JSON(fiber.Map{"success": false, "message": "Gagal menghapus"})
-    }
+	// Soft delete di Postgres; penghapusan konten Mongo diantrekan di outbox (transaksi yang sama)
+	err = repository.DeleteAchievementReference(database.DB, ref, newStatusEvent(c, &ref.Status, nil))
+	if err != nil {
+		return statusUpdateError(err, "Gagal menghapus")
+	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Deleted (Soft)"})
 }
@@ -192,55 +352,140 @@ func DeleteAchievement(c *fiber.Ctx) error {
 // POST /api/v1/achievements/:id/submit
 // SubmitAchievement godoc
 // @Summary      Submit ke Dosen Wali
-// @Description  Mengubah status Draft menjadi Submitted agar bisa diverifikasi
+// @Description  Mengubah status draft / revised menjadi submitted agar bisa diverifikasi. Konten saat submit disimpan sebagai revisi.
+// @Description  Ditolak (409) jika ada lampiran yang belum dipindai malware atau terinfeksi.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string true "Achievement ID"
 // @Success      200  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
 // @Router       /achievements/{id}/submit [post]
 func SubmitAchievement(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
+
+	next, err := NextAchievementStatus(ActionSubmit, ref.Status, actorKind(c))
+	if err != nil {
+		return workflowError(ref, err)
+	}
 
-	if ref.Status != model.StatusDraft {
-		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Hanya draft yang bisa disubmit"})
+	// Simpan snapshot konten yang disubmit (revisi) agar dosen bisa membandingkan antar submit
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return response.Internal("Gagal mengambil konten prestasi", err)
+	}
+	// Dosen hanya menerima lampiran yang sudah dipindai dan bersih
+	if err := CheckAttachmentsScanned(detail.Attachments); err != nil {
+		return err
+	}
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+	revision := &model.AchievementRevision{AchievementID: ref.ID, Content: *detail, SubmittedBy: userID}
+	if latest, err := repository.GetLatestAchievementVersion(database.MongoDB, ref.ID); err == nil {
+		revision.Version = latest.Version
+	}
+	if err := repository.InsertAchievementRevision(database.MongoDB, revision); err != nil {
+		return response.Internal("Gagal menyimpan revisi", err)
 	}
 
+	oldStatus := ref.Status
 	now := time.Now()
-	ref.Status = model.StatusSubmitted
+	ref.Status = next
 	ref.SubmittedAt = &now
-	repository.UpdateAchievementStatus(database.DB, ref)
+	// Keputusan sebelumnya (reject / request changes) sudah tercatat di history
+	ref.VerifiedAt = nil
+	ref.VerifiedBy = nil
+	ref.RejectionNote = nil
+	if err := repository.TransitionAchievementStatus(database.DB, ref, oldStatus, newStatusEvent(c, &oldStatus, nil), nil); err != nil {
+		return statusUpdateError(err, "Gagal mengubah status")
+	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Submitted for verification"})
+	return c.JSON(fiber.Map{"success": true, "message": "Submitted for verification", "data": fiber.Map{"revision": revision.Revision}})
 }
 
 // POST /api/v1/achievements/:id/verify
 // VerifyAchievement godoc
 // @Summary      Verifikasi Prestasi (Dosen)
-// @Description  Dosen menyetujui prestasi mahasiswa
+// @Description  Dosen menyetujui prestasi mahasiswa. Poin hasil aturan bisa disesuaikan dengan menyertakan points + justification.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string true "Achievement ID"
+// @Param        request body model.VerifyAchievementRequest false "Penyesuaian Poin (opsional)"
 // @Success      200  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /achievements/{id}/verify [post]
 func VerifyAchievement(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	verifierIDStr := c.Locals("user_id").(string) // Ambil ID Dosen dari Token
 	verifierID, _ := uuid.Parse(verifierIDStr)
 
+	// Body opsional: tanpa body = poin hasil aturan dipakai apa adanya
+	var req model.VerifyAchievementRequest
+	if len(c.Body()) > 0 {
+		if err := bindAndValidate(c, &req); err != nil {
+			return err
+		}
+	}
+	if req.Points != nil && strings.TrimSpace(req.Justification) == "" {
+		return response.Validation([]helper.FieldError{{Field: "justification", Message: "wajib diisi jika poin disesuaikan"}})
+	}
+
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
 
-	if ref.Status != model.StatusSubmitted {
-		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Status harus submitted"})
+	next, err := NextAchievementStatus(ActionVerify, ref.Status, actorKind(c))
+	if err != nil {
+		return workflowError(ref, err)
 	}
 
 	now := time.Now()
-	ref.Status = model.StatusVerified
+	var note *string
+	var adjustment *model.OutboxScoringPayload
+	var outbox *model.AchievementOutbox
+	if req.Points != nil {
+		doc, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+		if err != nil {
+			return response.Internal("Gagal mengambil konten prestasi", err)
+		}
+		scoring := model.AchievementScoring{ComputedPoints: doc.Points, CalculatedAt: now}
+		if doc.Scoring != nil {
+			scoring = *doc.Scoring
+		}
+		scoring.Adjustment = &model.PointsAdjustment{
+			Points: *req.Points, Justification: req.Justification, AdjustedBy: verifierID, AdjustedAt: now,
+		}
+		adjustment = &model.OutboxScoringPayload{Points: *req.Points, Scoring: scoring}
+		payload, err := json.Marshal(adjustment)
+		if err != nil {
+			return response.Internal("Gagal menyiapkan penyesuaian poin", err)
+		}
+		// Poin ditulis ke Mongo lewat outbox, hanya jika transisi verifikasi commit
+		outbox = &model.AchievementOutbox{Operation: model.OutboxMongoScoring, Payload: payload}
+		// Penyesuaian tercatat di history bersama event verifikasi
+		msg := fmt.Sprintf("Poin disesuaikan %d -> %d: %s", scoring.ComputedPoints, *req.Points, req.Justification)
+		note = &msg
+	}
+
+	oldStatus := ref.Status
+	ref.Status = next
 	ref.VerifiedAt = &now
 	ref.VerifiedBy = &verifierID
-	repository.UpdateAchievementStatus(database.DB, ref)
+	err = repository.TransitionAchievementStatus(database.DB, ref, oldStatus, newStatusEvent(c, &oldStatus, note), outbox)
+	if err != nil {
+		return statusUpdateError(err, "Gagal mengubah status")
+	}
+
+	// Langsung diterapkan agar poin baru terlihat; jika gagal, worker outbox mengulanginya
+	if adjustment != nil {
+		if err := repository.UpdateAchievementScoring(database.MongoDB, ref.MongoAchievementID, adjustment.Points, adjustment.Scoring); err != nil {
+			log.Printf("verifikasi %s: penyesuaian poin menunggu outbox: %v", ref.ID, err)
+		}
+	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Verified"})
 }
@@ -254,32 +499,87 @@ func VerifyAchievement(c *fiber.Ctx) error {
 // @Param        id   path      string true "Achievement ID"
 // @Param        request body model.RejectAchievementRequest true "Alasan Penolakan"
 // @Success      200  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /achievements/{id}/reject [post]
 func RejectAchievement(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
 	var req model.RejectAchievementRequest
-	c.BodyParser(&req)
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
 
 	verifierIDStr := c.Locals("user_id").(string)
 	verifierID, _ := uuid.Parse(verifierIDStr)
 
 	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
 
-	if ref.Status != model.StatusSubmitted {
-		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Status harus submitted"})
+	next, err := NextAchievementStatus(ActionReject, ref.Status, actorKind(c))
+	if err != nil {
+		return workflowError(ref, err)
 	}
 
+	oldStatus := ref.Status
 	now := time.Now()
-	ref.Status = model.StatusRejected
+	ref.Status = next
 	ref.VerifiedAt = &now
 	ref.VerifiedBy = &verifierID
 	ref.RejectionNote = &req.RejectionNote
-	repository.UpdateAchievementStatus(database.DB, ref)
+	if err := repository.TransitionAchievementStatus(database.DB, ref, oldStatus, newStatusEvent(c, &oldStatus, &req.RejectionNote), nil); err != nil {
+		return statusUpdateError(err, "Gagal mengubah status")
+	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Rejected"})
 }
 
+// POST /api/v1/achievements/:id/request-changes
+// RequestAchievementChanges godoc
+// @Summary      Minta Perbaikan (Dosen)
+// @Description  Dosen mengembalikan prestasi ke mahasiswa untuk diperbaiki (bukan penolakan final)
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true "Achievement ID"
+// @Param        request body model.RequestChangesRequest true "Catatan Perbaikan"
+// @Success      200  {object}  fiber.Map
+// @Failure      400  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /achievements/{id}/request-changes [post]
+func RequestAchievementChanges(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+	var req model.RequestChangesRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	reviewerID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
+
+	next, err := NextAchievementStatus(ActionRequestChanges, ref.Status, actorKind(c))
+	if err != nil {
+		return workflowError(ref, err)
+	}
+
+	oldStatus := ref.Status
+	now := time.Now()
+	ref.Status = next
+	ref.VerifiedAt = &now
+	ref.VerifiedBy = &reviewerID
+	ref.RejectionNote = &req.Note
+	if err := repository.TransitionAchievementStatus(database.DB, ref, oldStatus, newStatusEvent(c, &oldStatus, &req.Note), nil); err != nil {
+		return statusUpdateError(err, "Gagal mengubah status")
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Changes requested"})
+}
+
 // GET /api/v1/achievements/:id/history
 // GetHistory godoc
 // @Summary      Lihat History Status
@@ -287,71 +587,62 @@ func RejectAchievement(c *fiber.Ctx) error {
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string true "Achievement ID"
-// @Success      200  {array}   model.AchievementHistoryResponse
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementHistoryResponse}
+// @Failure      403  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
 // @Router       /achievements/{id}/history [get]
 func GetAchievementHistory(c *fiber.Ctx) error {
 	id, _ := uuid.Parse(c.Params("id"))
-	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
-
-	// Construct History Manual dari Data Reference
-	var history []model.AchievementHistoryResponse
-
-	// 1. Created
-	history = append(history, model.AchievementHistoryResponse{
-		Status: model.StatusDraft, Timestamp: ref.CreatedAt, Actor: "Mahasiswa",
-	})
-
-	// 2. Submitted
-	if ref.SubmittedAt != nil {
-		history = append(history, model.AchievementHistoryResponse{
-			Status: model.StatusSubmitted, Timestamp: *ref.SubmittedAt, Actor: "Mahasiswa",
-		})
-	}
-
-	// 3. Verified/Rejected
-	if ref.VerifiedAt != nil {
-		actor := "Dosen"
-		if ref.Status == model.StatusRejected {
-			history = append(history, model.AchievementHistoryResponse{
-				Status: model.StatusRejected, Timestamp: *ref.VerifiedAt, Actor: actor, Note: ref.RejectionNote,
-			})
-		} else {
-			history = append(history, model.AchievementHistoryResponse{
-				Status: model.StatusVerified, Timestamp: *ref.VerifiedAt, Actor: actor,
-			})
-		}
+
+	// History = event log status yang ditulis setiap transisi (achievement_status_events)
+	history, err := repository.GetAchievementHistory(database.DB, id)
+	if err != nil {
+		return response.Internal("Gagal mengambil history", err)
 	}
 
 	return c.JSON(fiber.Map{"success": true, "data": history})
 }
 
-// POST /api/v1/achievements/:id/attachments
-// UploadAttachment godoc
-// @Summary      Upload Bukti Dokumen
-// @Description  Upload file PDF/Gambar bukti prestasi
-// @Tags         Achievements
-// @Security     BearerAuth
-// @Accept       multipart/form-data
-// @Param        id    path      string  true  "Achievement ID"
-// @Param        file  formData  file    true  "Bukti File (PDF/JPG)"
-// @Success      200   {object}  fiber.Map
-// @Router       /achievements/{id}/attachments [post]
-func UploadAttachment(c *fiber.Ctx) error {
-	id, _ := uuid.Parse(c.Params("id"))
-	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
-	if err != nil { return c.SendStatus(404) }
-
-	file, err := c.FormFile("file")
-	if err != nil { return c.Status(400).JSON(fiber.Map{"message": "File required"})}
+// newStatusEvent menyiapkan data event log (aktor, role, request ID) untuk transisi status
+func newStatusEvent(c *fiber.Ctx, oldStatus *model.AchievementStatus, note *string) *model.AchievementStatusEvent {
+	event := &model.AchievementStatusEvent{OldStatus: oldStatus, Note: note}
+	if actor := currentActor(c); actor != nil {
+		userID := actor.UserID
+		event.ActorUserID = &userID
+		event.ActorRole = actorRoleLabel(actor)
+	}
+	if requestID, ok := c.Locals(requestid.ConfigDefault.ContextKey).(string); ok {
+		event.RequestID = requestID
+	}
+	return event
+}
 
-	filename := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-	c.SaveFile(file, filepath.Join("./uploads", filename))
+// applyEditTransition menyimpan perubahan status akibat edit (mis. rejected -> revised).
+// Mengembalikan response error (409 jika status sudah diubah request lain).
+func applyEditTransition(c *fiber.Ctx, ref *model.AchievementReference, next model.AchievementStatus) error {
+	if next == ref.Status {
+		return nil
+	}
+	oldStatus := ref.Status
+	ref.Status = next
+	if err := repository.TransitionAchievementStatus(database.DB, ref, oldStatus, newStatusEvent(c, &oldStatus, nil), nil); err != nil {
+		return statusUpdateError(err, "Gagal mengubah status")
+	}
+	return nil
+}
 
-	attachment := model.Attachment{
-		FileName: file.Filename, FileURL: "/uploads/"+filename, FileType: file.Header.Get("Content-Type"), UploadedAt: time.Now(),
+// statusUpdateError: 409 jika status sudah diubah request lain sejak dibaca, selain itu 500
+func statusUpdateError(err error, message string) error {
+	if errors.Is(err, repository.ErrAchievementStatusChanged) {
+		return response.Conflict("Status prestasi sudah berubah, muat ulang lalu coba lagi")
 	}
+	return response.Internal(message, err)
+}
 
-	repository.AddAttachmentMongo(database.MongoDB, ref.MongoAchievementID, attachment)
-	return c.JSON(fiber.Map{"success": true, "message": "File uploaded", "data": attachment})
-}
\ No newline at end of file
+// workflowErrorResponse: 403 jika aktor tidak berhak, 409 jika transisi tidak valid dari status saat ini
+func workflowError(ref *model.AchievementReference, err error) error {
+	if errors.Is(err, ErrActionNotAllowed) {
+		return response.Forbidden(err.Error()).With("current_status", ref.Status)
+	}
+	return response.Conflict(err.Error()).With("current_status", ref.Status)
+}
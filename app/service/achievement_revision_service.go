@@ -0,0 +1,123 @@
+package service
+
+import (
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GET /api/v1/achievements/:id/revisions
+// GetAchievementRevisions godoc
+// @Summary      Daftar Revisi Prestasi
+// @Description  Snapshot konten prestasi setiap kali disubmit (revisi 1 = submit pertama)
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true "Achievement ID"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementRevision}
+// @Router       /achievements/{id}/revisions [get]
+func GetAchievementRevisions(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+
+	revisions, err := repository.GetAchievementRevisions(database.MongoDB, id)
+	if err != nil {
+		return response.Internal("Gagal mengambil revisi", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "data": revisions})
+}
+
+// GET /api/v1/achievements/:id/revisions/diff?from=1&to=2
+// DiffAchievementRevisions godoc
+// @Summary      Bandingkan Dua Revisi
+// @Description  Perubahan per field antara dua revisi. Default: revisi terakhir dibandingkan dengan revisi sebelumnya.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true  "Achievement ID"
+// @Param        from query     int    false "Revisi awal"
+// @Param        to   query     int    false "Revisi akhir"
+// @Success      200  {object}  fiber.Map{data=[]helper.FieldChange}
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/{id}/revisions/diff [get]
+func DiffAchievementRevisions(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+
+	to, err := positiveIntQuery(c, "to")
+	if err != nil {
+		return response.BadRequest("Parameter 'to' tidak valid")
+	}
+	from, err := positiveIntQuery(c, "from")
+	if err != nil {
+		return response.BadRequest("Parameter 'from' tidak valid")
+	}
+
+	var newer *model.AchievementRevision
+	if to == 0 {
+		newer, err = repository.GetLatestAchievementRevision(database.MongoDB, id)
+	} else {
+		newer, err = repository.GetAchievementRevision(database.MongoDB, id, to)
+	}
+	if err == mongo.ErrNoDocuments {
+		return response.NotFound("Revisi tidak ditemukan")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil revisi", err)
+	}
+
+	if from == 0 {
+		from = newer.Revision - 1
+	}
+	if from < 1 {
+		return response.NotFound("Belum ada revisi sebelumnya untuk dibandingkan")
+	}
+	older, err := repository.GetAchievementRevision(database.MongoDB, id, from)
+	if err == mongo.ErrNoDocuments {
+		return response.NotFound("Revisi tidak ditemukan")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil revisi", err)
+	}
+
+	return diffAchievementContent(c, older.Revision, newer.Revision, older.Content, newer.Content)
+}
+
+// diffAchievementContent: Response perubahan per field antara dua snapshot konten
+func diffAchievementContent(c *fiber.Ctx, from, to int, older, newer model.Achievement) error {
+	changes, err := helper.DiffJSON(achievementContent(older), achievementContent(newer))
+	if err != nil {
+		return response.Internal("Gagal membandingkan konten", err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"from": from, "to": to, "changes": changes},
+	})
+}
+
+// achievementContent: Field konten yang dibandingkan (metadata seperti id / timestamp diabaikan)
+func achievementContent(a model.Achievement) fiber.Map {
+	return fiber.Map{
+		"title":            a.Title,
+		"description":      a.Description,
+		"achievement_type": a.AchievementType,
+		"details":          a.Details,
+		"tags":             a.Tags,
+		"points":           a.Points,
+		"attachments":      a.Attachments,
+	}
+}
+
+func positiveIntQuery(c *fiber.Ctx, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, strconv.ErrSyntax
+	}
+	return n, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// Hasil $text yang diproses per pencarian, setelah filter scope & status (facet & pagination
+	// dihitung dari sini; response truncated=true jika batas ini tercapai)
+	maxSearchCandidates = 500
+	searchSnippetLength = 160
+)
+
+// GET /api/v1/achievements/search?q=
+// SearchAchievements godoc
+// @Summary      Cari Prestasi (Full-text)
+// @Description  Pencarian di judul, tag, deskripsi, dan details sesuai scope aktor (Mahasiswa: milik sendiri, Dosen: bimbingan, Admin: semua), dengan facet dan highlight
+// @Description  Maksimal 500 hasil teratas yang diproses; truncated=true jika hasil lebih banyak (persempit kata kunci / filter)
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        q                 query  string  true   "Kata kunci (mendukung \"frasa\" dan -pengecualian)"
+// @Param        achievement_type  query  string  false  "Filter tipe prestasi"
+// @Param        tag               query  string  false  "Filter tag"
+// @Param        status            query  string  false  "Filter status (pisahkan koma)"
+// @Param        year              query  int     false  "Filter tahun dibuat"
+// @Param        page              query  int     false  "Halaman (default 1)"
+// @Param        limit             query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementSearchHit,facets=model.AchievementSearchFacets,truncated=bool,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
+// @Router       /achievements/search [get]
+func SearchAchievements(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return response.BadRequest("Parameter 'q' wajib diisi")
+	}
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	if params.After != "" {
+		return response.BadRequest("Pencarian memakai page/limit, bukan cursor")
+	}
+
+	filter := repository.AchievementSearchFilter{
+		Query:           query,
+		AchievementType: params.Filters["achievement_type"],
+		Tag:             params.Filters["tag"],
+	}
+	if raw := params.Filters["year"]; raw != "" {
+		if filter.Year, err = strconv.Atoi(raw); err != nil || filter.Year < 1 {
+			return response.BadRequest("Parameter 'year' tidak valid")
+		}
+	}
+
+	actor := currentActor(c)
+	empty := &model.ListResult[model.AchievementSearchHit]{Items: []model.AchievementSearchHit{}}
+	switch {
+	case actor.IsAdmin():
+	case actor != nil && actor.Kind == model.ActorStudent && actor.StudentID != nil:
+		filter.StudentIDs = []uuid.UUID{*actor.StudentID}
+	case actor != nil && actor.Kind == model.ActorLecturer && actor.LecturerID != nil:
+		if filter.StudentIDs, err = repository.GetAdviseeStudentIDs(database.DB, *actor.LecturerID); err != nil {
+			return response.Internal("Gagal mengambil data bimbingan", err)
+		}
+	default:
+		return listResponseWith(c, params, empty, fiber.Map{"facets": buildSearchFacets(nil), "truncated": false})
+	}
+
+	// Status ada di Postgres: filter status & draft (untuk dosen) diterapkan lewat daftar dokumen yang lolos,
+	// agar batas kandidat Mongo berlaku setelah semua filter
+	statuses := map[string]bool{}
+	var statusFilter, exclude []model.AchievementStatus
+	if raw := params.Filters["status"]; raw != "" {
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			statuses[s] = true
+			statusFilter = append(statusFilter, model.AchievementStatus(s))
+		}
+	}
+	if actor.Kind == model.ActorLecturer {
+		exclude = []model.AchievementStatus{model.StatusDraft}
+	}
+	if len(statusFilter) > 0 || len(exclude) > 0 {
+		if filter.MongoIDs, err = repository.GetAchievementMongoIDsInScope(database.DB, filter.StudentIDs, statusFilter, exclude); err != nil {
+			return response.Internal("Gagal mengambil status prestasi", err)
+		}
+		if len(filter.MongoIDs) == 0 {
+			return listResponseWith(c, params, empty, fiber.Map{"facets": buildSearchFacets(nil), "truncated": false})
+		}
+	}
+
+	docs, err := repository.SearchAchievementsMongo(database.MongoDB, filter, maxSearchCandidates+1)
+	if err != nil {
+		return response.Internal("Gagal mencari prestasi", err)
+	}
+	truncated := len(docs) > maxSearchCandidates
+	if truncated {
+		docs = docs[:maxSearchCandidates]
+	}
+
+	// Gabungkan status dari Postgres; cek ulang karena reference bisa berubah di antara dua query
+	mongoIDs := make([]string, len(docs))
+	for i, d := range docs {
+		mongoIDs[i] = d.ID.Hex()
+	}
+	refs, err := repository.GetAchievementReferencesByMongoIDs(database.DB, mongoIDs)
+	if err != nil {
+		return response.Internal("Gagal mengambil status prestasi", err)
+	}
+
+	terms := helper.SearchTerms(query)
+	hits := []model.AchievementSearchHit{}
+	for _, d := range docs {
+		ref, ok := refs[d.ID.Hex()]
+		if !ok {
+			continue
+		}
+		if actor.Kind == model.ActorLecturer && ref.Status == model.StatusDraft {
+			continue
+		}
+		if len(statuses) > 0 && !statuses[string(ref.Status)] {
+			continue
+		}
+		hits = append(hits, newSearchHit(ref, d, terms))
+	}
+
+	result := &model.ListResult[model.AchievementSearchHit]{Items: []model.AchievementSearchHit{}, Total: len(hits)}
+	if from := (params.Page - 1) * params.Limit; from < len(hits) {
+		to := from + params.Limit
+		if to > len(hits) {
+			to = len(hits)
+		}
+		result.Items = hits[from:to]
+	}
+	return listResponseWith(c, params, result, fiber.Map{"facets": buildSearchFacets(hits), "truncated": truncated})
+}
+
+func newSearchHit(ref model.AchievementReference, d repository.AchievementSearchDoc, terms []string) model.AchievementSearchHit {
+	hit := model.AchievementSearchHit{
+		ID: ref.ID, StudentID: ref.StudentID, Status: ref.Status,
+		Title: d.Title, AchievementType: d.AchievementType, Tags: d.Tags, Points: d.Points,
+		Score: d.Score, CreatedAt: d.CreatedAt, Highlights: map[string]string{},
+	}
+	if hit.Tags == nil {
+		hit.Tags = []string{}
+	}
+
+	fields := map[string]string{"title": d.Title, "description": d.Description, "tags": strings.Join(d.Tags, ", ")}
+	for key, value := range d.Details {
+		if text, ok := value.(string); ok {
+			fields["details."+key] = text
+		}
+	}
+	for field, text := range fields {
+		if snippet, ok := helper.Highlight(text, terms, searchSnippetLength); ok {
+			hit.Highlights[field] = snippet
+		}
+	}
+	return hit
+}
+
+// buildSearchFacets menghitung facet dari seluruh hasil yang lolos filter
+func buildSearchFacets(hits []model.AchievementSearchHit) model.AchievementSearchFacets {
+	types, tags, statuses, years := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	for _, h := range hits {
+		types[h.AchievementType]++
+		statuses[string(h.Status)]++
+		years[fmt.Sprint(h.CreatedAt.Year())]++
+		for _, tag := range h.Tags {
+			tags[tag]++
+		}
+	}
+	return model.AchievementSearchFacets{
+		AchievementType: sortedFacet(types),
+		Tag:             sortedFacet(tags),
+		Status:          sortedFacet(statuses),
+		Year:            sortedFacet(years),
+	}
+}
+
+// sortedFacet: Urut jumlah terbanyak, lalu nilai
+func sortedFacet(counts map[string]int) []model.FacetCount {
+	facet := make([]model.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facet = append(facet, model.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facet, func(i, j int) bool {
+		if facet[i].Count != facet[j].Count {
+			return facet[i].Count > facet[j].Count
+		}
+		return facet[i].Value < facet[j].Value
+	})
+	return facet
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	purgeBatchSize            = 100
+)
+
+// TrashRetention: Lama item disimpan di trash sebelum dihapus permanen (ENV ACHIEVEMENT_TRASH_RETENTION_DAYS)
+func TrashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if raw := os.Getenv("ACHIEVEMENT_TRASH_RETENTION_DAYS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			days = n
+		} else {
+			log.Printf("ACHIEVEMENT_TRASH_RETENTION_DAYS tidak valid (%q), memakai %d hari", raw, defaultTrashRetentionDays)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GET /api/v1/achievements/trash
+// ListTrashedAchievements godoc
+// @Summary      Trash Prestasi (Admin)
+// @Description  Daftar prestasi yang di-soft delete beserta status sebelumnya dan jadwal hapus permanen
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        sort   query  string  false  "deleted_at, created_at (prefix - untuk desc)"
+// @Success      200  {object}  fiber.Map{data=[]model.TrashedAchievement,meta=model.ListMeta,links=model.ListLinks}
+// @Router       /achievements/trash [get]
+func ListTrashedAchievements(c *fiber.Ctx) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	result, err := repository.GetDeletedAchievementReferences(database.DB, params)
+	if err != nil {
+		return listError(err, "Gagal mengambil trash")
+	}
+
+	retention := TrashRetention()
+	for i := range result.Items {
+		if result.Items[i].DeletedAt != nil {
+			purgeAt := result.Items[i].DeletedAt.Add(retention)
+			result.Items[i].PurgeAt = &purgeAt
+		}
+	}
+	return listResponse(c, params, result)
+}
+
+// POST /api/v1/achievements/trash/:id/restore
+// RestoreAchievement godoc
+// @Summary      Restore Prestasi (Admin)
+// @Description  Mengembalikan prestasi dari trash ke status sebelum dihapus
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true "Achievement ID"
+// @Success      200  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Router       /achievements/trash/{id}/restore [post]
+func RestoreAchievement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest("ID tidak valid")
+	}
+
+	item, err := repository.GetDeletedAchievementReferenceByID(database.DB, id)
+	if err == sql.ErrNoRows {
+		return response.NotFound("Prestasi tidak ada di trash")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil data", err)
+	}
+
+	// Data lama (sebelum soft delete Mongo) kontennya sudah terhapus permanen
+	if _, err := repository.GetAchievementMongoByID(database.MongoDB, item.MongoAchievementID); err == mongo.ErrNoDocuments {
+		return response.Conflict("Konten prestasi sudah terhapus permanen, tidak bisa di-restore")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil konten prestasi", err)
+	}
+
+	ref := item.AchievementReference
+	ref.Status = model.StatusDraft
+	if item.PreviousStatus != nil {
+		ref.Status = *item.PreviousStatus
+	}
+
+	oldStatus := model.StatusDeleted
+	note := "restore dari trash"
+	if err := repository.RestoreAchievementReference(database.DB, &ref, newStatusEvent(c, &oldStatus, &note)); err == sql.ErrNoRows {
+		return response.Conflict("Prestasi sudah di-restore")
+	} else if err != nil {
+		return response.Internal("Gagal restore prestasi", err)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Restored", "data": ref})
+}
+
+// DELETE /api/v1/achievements/trash/:id
+// PurgeAchievement godoc
+// @Summary      Hapus Permanen (Admin)
+// @Description  Menghapus permanen prestasi di trash (Postgres, Mongo, versi/revisi, dan file lampiran)
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true "Achievement ID"
+// @Success      200  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/trash/{id} [delete]
+func PurgeAchievement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest("ID tidak valid")
+	}
+
+	item, err := repository.GetDeletedAchievementReferenceByID(database.DB, id)
+	if err == sql.ErrNoRows {
+		return response.NotFound("Prestasi tidak ada di trash")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil data", err)
+	}
+
+	if err := purgeAchievement(&item.AchievementReference); err != nil {
+		return response.Internal("Gagal menghapus permanen", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Purged"})
+}
+
+// StartAchievementPurgeWorker menghapus permanen item trash yang melewati masa retensi
+func StartAchievementPurgeWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := PurgeExpiredAchievements(); err != nil {
+				log.Println("purge trash prestasi:", err)
+			} else if n > 0 {
+				log.Printf("purge trash prestasi: %d item dihapus permanen", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeExpiredAchievements menjalankan satu batch purge berdasarkan TrashRetention
+func PurgeExpiredAchievements() (int, error) {
+	refs, err := repository.GetPurgeableAchievementReferences(database.DB, time.Now().Add(-TrashRetention()), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range refs {
+		if err := purgeAchievement(&refs[i]); err != nil {
+			return purged, fmt.Errorf("purge %s: %w", refs[i].ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeAchievement: File di storage & Mongo dihapus dulu, baru reference. Jika ada langkah yang gagal,
+// item tetap di trash dan purge berikutnya aman diulang (semua langkah idempotent).
+func purgeAchievement(ref *model.AchievementReference) error {
+	files := map[string]model.Attachment{}
+	if doc, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID); err == nil {
+		collectAttachmentFiles(files, doc.Attachments)
+	} else if err != mongo.ErrNoDocuments {
+		return err
+	}
+	versions, err := repository.GetAchievementVersions(database.MongoDB, ref.ID)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		collectAttachmentFiles(files, v.Content.Attachments)
+	}
+
+	// File (dan preview) yang juga dipakai prestasi lain tidak ikut dihapus
+	for _, a := range files {
+		if err := deleteUnreferencedBlob(context.Background(), &a, ref.MongoAchievementID); err != nil {
+			return err
+		}
+	}
+	if err := repository.DeleteAchievementMongo(database.MongoDB, ref.MongoAchievementID); err != nil {
+		return err
+	}
+	if err := repository.DeleteAchievementVersions(database.MongoDB, ref.ID); err != nil {
+		return err
+	}
+	if err := repository.DeleteAchievementRevisions(database.MongoDB, ref.ID); err != nil {
+		return err
+	}
+	return repository.PurgeAchievementReference(database.DB, ref.ID)
+}
+
+// collectAttachmentFiles mengumpulkan lampiran unik per key storage (lihat attachmentStorageKey)
+func collectAttachmentFiles(files map[string]model.Attachment, attachments []model.Attachment) {
+	for _, a := range attachments {
+		files[attachmentStorageKey(&a)] = a
+	}
+}
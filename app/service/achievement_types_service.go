@@ -0,0 +1,252 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cache registry tipe prestasi (code -> tipe + schema yang sudah di-parse).
+// Di-invalidate saat tipe diubah lewat API, TTL untuk perubahan dari instance lain.
+const achievementTypeCacheTTL = 5 * time.Minute
+
+var achievementTypeCache = helper.NewTTLCache[string, *registeredAchievementType]()
+
+var achievementTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type registeredAchievementType struct {
+	Type   model.AchievementType
+	Schema *helper.Schema
+}
+
+// errUnknownAchievementType: Code tidak ada di registry
+var errUnknownAchievementType = errors.New("tipe prestasi tidak dikenal")
+
+func getRegisteredAchievementType(code string) (*registeredAchievementType, error) {
+	if t, ok := achievementTypeCache.Get(code); ok {
+		return t, nil
+	}
+
+	t, err := repository.GetAchievementTypeByCode(database.DB, code)
+	if err == sql.ErrNoRows {
+		return nil, errUnknownAchievementType
+	}
+	if err != nil {
+		return nil, err
+	}
+	schema, err := parseDetailsSchema(t.DetailsSchema)
+	if err != nil {
+		return nil, err
+	}
+	reg := &registeredAchievementType{Type: *t, Schema: schema}
+	achievementTypeCache.Set(code, reg, achievementTypeCacheTTL)
+	return reg, nil
+}
+
+// parseDetailsSchema: Schema details harus valid dan berupa object
+func parseDetailsSchema(raw json.RawMessage) (*helper.Schema, error) {
+	schema, err := helper.ParseSchema(raw)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type != "object" {
+		return nil, errors.New("details_schema harus bertipe object")
+	}
+	return schema, nil
+}
+
+// validateAchievementDetails memeriksa tipe & details prestasi terhadap registry.
+// requireActive: tipe nonaktif tidak bisa dipakai untuk prestasi baru / pindah tipe,
+// tetapi prestasi lama dengan tipe tersebut tetap bisa diedit.
+func validateAchievementDetails(code string, details map[string]interface{}, requireActive bool) ([]helper.FieldError, error) {
+	reg, err := getRegisteredAchievementType(code)
+	if err == errUnknownAchievementType {
+		return []helper.FieldError{{Field: "achievement_type", Message: "tipe prestasi tidak dikenal"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if requireActive && !reg.Type.IsActive {
+		return []helper.FieldError{{Field: "achievement_type", Message: "tipe prestasi sudah tidak aktif"}}, nil
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	return reg.Schema.Validate(details, "details"), nil
+}
+
+// GetAllAchievementTypes godoc
+// @Summary      Lihat Tipe Prestasi
+// @Description  Daftar tipe prestasi beserta JSON Schema details (untuk render form)
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page       query  int     false  "Halaman (default 1)"
+// @Param        limit      query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after      query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort       query  string  false  "code, name, created_at (prefix - untuk desc)"
+// @Param        name       query  string  false  "Cari nama tipe (mengandung)"
+// @Param        is_active  query  bool    false  "Filter status aktif"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementType,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
+// @Failure      500  {object}  fiber.Map
+// @Router       /achievement-types [get]
+func GetAllAchievementTypes(c *fiber.Ctx) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	types, err := repository.GetAllAchievementTypes(database.DB, params)
+	if err != nil {
+		return listError(err, "Gagal mengambil data tipe prestasi")
+	}
+	return listResponse(c, params, types)
+}
+
+// GetAchievementType godoc
+// @Summary      Detail Tipe Prestasi
+// @Description  Mendapatkan tipe prestasi beserta JSON Schema details berdasarkan code
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        code  path  string  true  "Code tipe (mis. competition)"
+// @Success      200  {object}  fiber.Map{data=model.AchievementType}
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievement-types/{code} [get]
+func GetAchievementType(c *fiber.Ctx) error {
+	t, err := repository.GetAchievementTypeByCode(database.DB, c.Params("code"))
+	if err != nil {
+		return response.NotFound("Tipe prestasi tidak ditemukan")
+	}
+	return c.JSON(fiber.Map{"success": true, "data": t})
+}
+
+// CreateAchievementType godoc
+// @Summary      Tambah Tipe Prestasi
+// @Description  Menambahkan tipe prestasi baru beserta JSON Schema details (Admin)
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.CreateAchievementTypeRequest true "Data Tipe"
+// @Success      201  {object}  fiber.Map{data=model.AchievementType}
+// @Failure      400  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /achievement-types [post]
+func CreateAchievementType(c *fiber.Ctx) error {
+	var req model.CreateAchievementTypeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var errs []helper.FieldError
+	if !achievementTypeCodePattern.MatchString(req.Code) {
+		errs = append(errs, helper.FieldError{Field: "code", Message: "harus huruf kecil, angka atau _ (2-50 karakter, diawali huruf)"})
+	}
+	if len(req.DetailsSchema) == 0 {
+		req.DetailsSchema = json.RawMessage(`{"type":"object"}`)
+	}
+	if _, err := parseDetailsSchema(req.DetailsSchema); err != nil {
+		errs = append(errs, helper.FieldError{Field: "details_schema", Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		return response.Validation(errs)
+	}
+
+	t := &model.AchievementType{
+		Code: req.Code, Name: req.Name, Description: req.Description,
+		DetailsSchema: req.DetailsSchema, IsActive: true,
+	}
+	if err := repository.CreateAchievementType(database.DB, t); err != nil {
+		if response.IsUniqueViolation(err) {
+			return response.Conflict("Code tipe prestasi sudah dipakai")
+		}
+		return response.FromDB(err, "Gagal menambah tipe prestasi")
+	}
+	achievementTypeCache.Delete(t.Code)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Tipe prestasi berhasil ditambahkan", "data": t})
+}
+
+// UpdateAchievementType godoc
+// @Summary      Update Tipe Prestasi
+// @Description  Mengubah nama, deskripsi, JSON Schema details atau status aktif (Admin). Schema baru hanya berlaku untuk create / edit berikutnya.
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        code  path  string  true  "Code tipe"
+// @Param        request body model.UpdateAchievementTypeRequest true "Data Update"
+// @Success      200  {object}  fiber.Map{data=model.AchievementType}
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /achievement-types/{code} [put]
+func UpdateAchievementType(c *fiber.Ctx) error {
+	var req model.UpdateAchievementTypeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	t, err := repository.GetAchievementTypeByCode(database.DB, c.Params("code"))
+	if err != nil {
+		return response.NotFound("Tipe prestasi tidak ditemukan")
+	}
+
+	if req.Name != "" {
+		t.Name = req.Name
+	}
+	if req.Description != nil {
+		t.Description = req.Description
+	}
+	if len(req.DetailsSchema) > 0 {
+		if _, err := parseDetailsSchema(req.DetailsSchema); err != nil {
+			return response.Validation([]helper.FieldError{{Field: "details_schema", Message: err.Error()}})
+		}
+		t.DetailsSchema = req.DetailsSchema
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if err := repository.UpdateAchievementType(database.DB, t); err != nil {
+		return response.FromDB(err, "Gagal mengupdate tipe prestasi")
+	}
+	achievementTypeCache.Delete(t.Code)
+
+	return c.JSON(fiber.Map{"success": true, "message": "Tipe prestasi berhasil diupdate", "data": t})
+}
+
+// DeleteAchievementType godoc
+// @Summary      Nonaktifkan Tipe Prestasi
+// @Description  Menonaktifkan tipe prestasi (Admin). Tidak dihapus permanen karena code masih dipakai prestasi lama.
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Param        code  path  string  true  "Code tipe"
+// @Success      200  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievement-types/{code} [delete]
+func DeleteAchievementType(c *fiber.Ctx) error {
+	t, err := repository.GetAchievementTypeByCode(database.DB, c.Params("code"))
+	if err != nil {
+		return response.NotFound("Tipe prestasi tidak ditemukan")
+	}
+
+	t.IsActive = false
+	if err := repository.UpdateAchievementType(database.DB, t); err != nil {
+		return response.FromDB(err, "Gagal menonaktifkan tipe prestasi")
+	}
+	achievementTypeCache.Delete(t.Code)
+
+	return c.JSON(fiber.Map{"success": true, "message": "Tipe prestasi berhasil dinonaktifkan"})
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GET /api/v1/achievements/:id/versions
+// ListAchievementVersions godoc
+// @Summary      Riwayat Versi Konten
+// @Description  Setiap perubahan konten (create, update, upload lampiran) disimpan sebagai versi baru
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true "Achievement ID"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementVersion}
+// @Router       /achievements/{id}/versions [get]
+func ListAchievementVersions(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+
+	versions, err := repository.GetAchievementVersions(database.MongoDB, id)
+	if err != nil {
+		return response.Internal("Gagal mengambil versi", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "data": versions})
+}
+
+// GET /api/v1/achievements/:id/versions/:version
+// GetAchievementVersion godoc
+// @Summary      Detail Satu Versi
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id       path      string true "Achievement ID"
+// @Param        version  path      int    true "Nomor versi"
+// @Success      200  {object}  fiber.Map{data=model.AchievementVersion}
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/{id}/versions/{version} [get]
+func GetAchievementVersion(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+	number, err := strconv.Atoi(c.Params("version"))
+	if err != nil || number < 1 {
+		return response.BadRequest("Nomor versi tidak valid")
+	}
+
+	version, err := repository.GetAchievementVersion(database.MongoDB, id, number)
+	if err == mongo.ErrNoDocuments {
+		return response.NotFound("Versi tidak ditemukan")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil versi", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "data": version})
+}
+
+// GET /api/v1/achievements/:id/versions/diff?from=1&to=3
+// DiffAchievementVersions godoc
+// @Summary      Bandingkan Dua Versi
+// @Description  Perubahan per field (termasuk details, tags, points, attachments). Default: versi terakhir dibandingkan dengan versi sebelumnya.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string true  "Achievement ID"
+// @Param        from query     int    false "Versi awal"
+// @Param        to   query     int    false "Versi akhir"
+// @Success      200  {object}  fiber.Map{data=[]helper.FieldChange}
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/{id}/versions/diff [get]
+func DiffAchievementVersions(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+
+	to, err := positiveIntQuery(c, "to")
+	if err != nil {
+		return response.BadRequest("Parameter 'to' tidak valid")
+	}
+	from, err := positiveIntQuery(c, "from")
+	if err != nil {
+		return response.BadRequest("Parameter 'from' tidak valid")
+	}
+
+	var newer *model.AchievementVersion
+	if to == 0 {
+		newer, err = repository.GetLatestAchievementVersion(database.MongoDB, id)
+	} else {
+		newer, err = repository.GetAchievementVersion(database.MongoDB, id, to)
+	}
+	if err == mongo.ErrNoDocuments {
+		return response.NotFound("Versi tidak ditemukan")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil versi", err)
+	}
+
+	if from == 0 {
+		from = newer.Version - 1
+	}
+	if from < 1 {
+		return response.NotFound("Belum ada versi sebelumnya untuk dibandingkan")
+	}
+	older, err := repository.GetAchievementVersion(database.MongoDB, id, from)
+	if err == mongo.ErrNoDocuments {
+		return response.NotFound("Versi tidak ditemukan")
+	} else if err != nil {
+		return response.Internal("Gagal mengambil versi", err)
+	}
+
+	return diffAchievementContent(c, older.Version, newer.Version, older.Content, newer.Content)
+}
+
+// ensureBaselineVersion menyimpan konten saat ini sebagai versi 1 untuk prestasi
+// yang dibuat sebelum versioning ada, agar perubahan pertama tetap bisa di-diff.
+func ensureBaselineVersion(ref *model.AchievementReference) error {
+	_, err := repository.GetLatestAchievementVersion(database.MongoDB, ref.ID)
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return err
+	}
+	return repository.InsertAchievementVersion(database.MongoDB, &model.AchievementVersion{
+		AchievementID: ref.ID, Action: model.VersionActionBaseline, Content: *detail,
+	})
+}
+
+// recordAchievementVersion menyimpan konten Mongo terbaru sebagai versi baru. Mengembalikan response error (500)
+// agar kegagalan tidak tersembunyi di balik response sukses; perubahan berikutnya tetap membuat versi dari konten terbaru.
+func recordAchievementVersion(c *fiber.Ctx, ref *model.AchievementReference, action model.AchievementVersionAction) (*model.AchievementVersion, error) {
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return nil, response.Internal("Konten tersimpan, tetapi gagal membaca konten untuk versi", err)
+	}
+
+	version := &model.AchievementVersion{AchievementID: ref.ID, Action: action, Content: *detail}
+	if actor := currentActor(c); actor != nil {
+		userID := actor.UserID
+		version.AuthorID = &userID
+		version.AuthorRole = actorRoleLabel(actor)
+	}
+	if err := repository.InsertAchievementVersion(database.MongoDB, version); err != nil {
+		return nil, response.Internal("Konten tersimpan, tetapi gagal menyimpan versi", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"project-uas/app/model"
+)
+
+// AchievementAction: Aksi yang bisa mengubah status prestasi
+type AchievementAction string
+
+const (
+	ActionEdit           AchievementAction = "edit" // Update konten / upload lampiran
+	ActionSubmit         AchievementAction = "submit"
+	ActionVerify         AchievementAction = "verify"
+	ActionReject         AchievementAction = "reject"
+	ActionRequestChanges AchievementAction = "request_changes"
+	ActionDelete         AchievementAction = "delete"
+)
+
+type workflowRule struct {
+	actors      []model.ActorKind
+	transitions map[model.AchievementStatus]model.AchievementStatus // from -> to
+}
+
+// achievementWorkflow: State machine prestasi (satu-satunya sumber aturan transisi)
+//
+//	draft ──submit──> submitted ──verify──────────> verified
+//	                     ├──reject──────────> rejected ───────────┐
+//	                     └──request_changes─> changes_requested ──┴─edit─> revised ──submit──> submitted
+var achievementWorkflow = map[AchievementAction]workflowRule{
+	ActionEdit: {
+		actors: []model.ActorKind{model.ActorStudent, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{
+			model.StatusDraft:            model.StatusDraft,
+			model.StatusRevised:          model.StatusRevised,
+			model.StatusRejected:         model.StatusRevised,
+			model.StatusChangesRequested: model.StatusRevised,
+		},
+	},
+	ActionSubmit: {
+		actors: []model.ActorKind{model.ActorStudent, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{
+			model.StatusDraft:   model.StatusSubmitted,
+			model.StatusRevised: model.StatusSubmitted,
+		},
+	},
+	ActionVerify: {
+		actors:      []model.ActorKind{model.ActorLecturer, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{model.StatusSubmitted: model.StatusVerified},
+	},
+	ActionReject: {
+		actors:      []model.ActorKind{model.ActorLecturer, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{model.StatusSubmitted: model.StatusRejected},
+	},
+	ActionRequestChanges: {
+		actors:      []model.ActorKind{model.ActorLecturer, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{model.StatusSubmitted: model.StatusChangesRequested},
+	},
+	ActionDelete: {
+		actors:      []model.ActorKind{model.ActorStudent, model.ActorAdmin},
+		transitions: map[model.AchievementStatus]model.AchievementStatus{model.StatusDraft: model.StatusDeleted},
+	},
+}
+
+var (
+	ErrActionNotAllowed  = errors.New("aksi tidak diizinkan untuk role ini")
+	ErrIllegalTransition = errors.New("transisi status tidak valid")
+)
+
+// NextAchievementStatus mengembalikan status tujuan dari sebuah aksi.
+// Error ErrActionNotAllowed (-> 403) atau ErrIllegalTransition (-> 409).
+func NextAchievementStatus(action AchievementAction, from model.AchievementStatus, actor model.ActorKind) (model.AchievementStatus, error) {
+	rule, ok := achievementWorkflow[action]
+	if !ok {
+		return "", fmt.Errorf("%w: aksi %q tidak dikenal", ErrIllegalTransition, action)
+	}
+
+	allowed := false
+	for _, a := range rule.actors {
+		if a == actor {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("%w: %s tidak bisa melakukan %s", ErrActionNotAllowed, actor, action)
+	}
+
+	to, ok := rule.transitions[from]
+	if !ok {
+		return "", fmt.Errorf("%w: tidak bisa %s prestasi berstatus %s", ErrIllegalTransition, action, from)
+	}
+	return to, nil
+}
+
+// IsAchievementEditable: true jika konten prestasi boleh diubah pada status ini
+func IsAchievementEditable(status model.AchievementStatus) bool {
+	_, ok := achievementWorkflow[ActionEdit].transitions[status]
+	return ok
+}
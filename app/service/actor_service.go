@@ -0,0 +1,165 @@
+package service
+
+import (
+	"database/sql"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/database"
+	"project-uas/helper"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Pemetaan nama role -> jenis aktor. Nama dibandingkan dalam huruf kecil.
+// Role yang tidak ada di sini dianggap ActorCustom (hanya akses berbasis permission).
+var roleKindByName = map[string]model.ActorKind{
+	strings.ToLower(database.RoleAdmin):    model.ActorAdmin,
+	strings.ToLower(database.RoleStudent):  model.ActorStudent,
+	strings.ToLower(database.RoleLecturer): model.ActorLecturer,
+	"admin":                                model.ActorAdmin,
+	"student":                              model.ActorStudent,
+	"mahasiswa":                            model.ActorStudent,
+	"lecturer":                             model.ActorLecturer,
+	"dosen":                                model.ActorLecturer,
+}
+
+type roleInfo struct {
+	Name string
+	Kind model.ActorKind
+}
+
+var roleInfoCache = helper.NewTTLCache[uuid.UUID, roleInfo]()
+
+// ResolveActor memetakan user + role menjadi Actor beserta profil student/lecturer-nya
+func ResolveActor(userID, roleID uuid.UUID) (*model.Actor, error) {
+	info, ok := roleInfoCache.Get(roleID)
+	if !ok {
+		role, err := repository.GetRoleByID(database.DB, roleID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		info = roleInfo{Kind: model.ActorCustom}
+		if role != nil {
+			info.Name = role.Name
+			if kind, found := roleKindByName[strings.ToLower(strings.TrimSpace(role.Name))]; found {
+				info.Kind = kind
+			}
+		}
+		roleInfoCache.Set(roleID, info, permissionCacheTTL)
+	}
+
+	actor := &model.Actor{UserID: userID, RoleID: roleID, RoleName: info.Name, Kind: info.Kind}
+
+	switch actor.Kind {
+	case model.ActorStudent:
+		student, err := repository.GetStudentByID(database.DB, userID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if student != nil {
+			actor.StudentID = &student.ID
+		}
+	case model.ActorLecturer:
+		lecturer, err := repository.GetLecturerByID(database.DB, userID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if lecturer != nil {
+			actor.LecturerID = &lecturer.ID
+		}
+	}
+	return actor, nil
+}
+
+// currentActor mengambil Actor dari Locals (diset oleh middleware.AuthProtected)
+func currentActor(c *fiber.Ctx) *model.Actor {
+	actor, _ := c.Locals("actor").(*model.Actor)
+	return actor
+}
+
+// actorKind: Jenis aktor dari Locals (kosong jika tidak ada aktor)
+func actorKind(c *fiber.Ctx) model.ActorKind {
+	if actor := currentActor(c); actor != nil {
+		return actor.Kind
+	}
+	return ""
+}
+
+// actorRoleLabel: Label role untuk audit log (nama role asli untuk role custom)
+func actorRoleLabel(actor *model.Actor) string {
+	if actor.Kind == model.ActorCustom {
+		return actor.RoleName
+	}
+	return string(actor.Kind)
+}
+
+// StudentListScopeFor: Batas daftar mahasiswa sesuai aktor (admin semua, mahasiswa dirinya sendiri,
+// dosen wali mahasiswa bimbingannya). ok = false jika aktor tidak boleh melihat data mahasiswa.
+func StudentListScopeFor(actor *model.Actor) (scope repository.StudentListScope, ok bool) {
+	switch {
+	case actor == nil:
+		return scope, false
+	case actor.IsAdmin():
+		return scope, true
+	case actor.Kind == model.ActorStudent && actor.StudentID != nil:
+		scope.StudentID = actor.StudentID
+		return scope, true
+	case actor.Kind == model.ActorLecturer && actor.LecturerID != nil:
+		scope.AdvisorID = actor.LecturerID
+		return scope, true
+	}
+	return scope, false
+}
+
+// CanViewAdvisees: Daftar bimbingan hanya untuk admin dan dosen yang bersangkutan
+func CanViewAdvisees(actor *model.Actor, lecturer *model.Lecturer) bool {
+	switch {
+	case actor == nil || lecturer == nil:
+		return false
+	case actor.IsAdmin():
+		return true
+	case actor.Kind == model.ActorLecturer:
+		return actor.LecturerID != nil && *actor.LecturerID == lecturer.ID
+	}
+	return false
+}
+
+// visibleLecturerID: Dosen yang boleh dilihat aktor. all = true untuk admin; dosen melihat dirinya sendiri,
+// mahasiswa melihat dosen walinya. id = nil & all = false jika aktor tidak boleh melihat data dosen.
+func visibleLecturerID(actor *model.Actor) (id *uuid.UUID, all bool, err error) {
+	switch {
+	case actor == nil:
+		return nil, false, nil
+	case actor.IsAdmin():
+		return nil, true, nil
+	case actor.Kind == model.ActorLecturer:
+		return actor.LecturerID, false, nil
+	case actor.Kind == model.ActorStudent:
+		student, err := repository.GetStudentByID(database.DB, actor.UserID)
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return student.AdvisorID, false, nil
+	}
+	return nil, false, nil
+}
+
+// CanViewStudentData: Admin semua, mahasiswa dirinya sendiri, dosen wali mahasiswa bimbingannya
+func CanViewStudentData(actor *model.Actor, student *model.Student) bool {
+	switch {
+	case actor == nil || student == nil:
+		return false
+	case actor.IsAdmin():
+		return true
+	case actor.IsStudentOwner(student.ID):
+		return true
+	case actor.Kind == model.ActorLecturer:
+		return actor.LecturerID != nil && student.AdvisorID != nil && *student.AdvisorID == *actor.LecturerID
+	}
+	return false
+}
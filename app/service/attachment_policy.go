@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"project-uas/app/model"
+	"project-uas/app/response"
+	"project-uas/helper"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Default kebijakan lampiran (bisa diubah lewat ENV, lihat LoadAttachmentPolicy)
+const (
+	defaultAttachmentTypes      = "application/pdf=10MB,image/jpeg=5MB,image/png=5MB"
+	defaultAttachmentMaxCount   = 10
+	defaultAttachmentMaxTotal   = 50 << 20
+	attachmentMultipartOverhead = 1 << 20 // Ruang untuk boundary & field lain di body multipart
+)
+
+// Ekstensi yang diterima per tipe; elemen pertama dipakai jika nama file dari client tidak cocok
+var attachmentExtensions = map[string][]string{
+	"application/pdf": {".pdf"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+}
+
+// AttachmentPolicy: Batas lampiran per prestasi
+type AttachmentPolicy struct {
+	MaxSizes     map[string]int64 // Tipe MIME (hasil sniffing) yang diizinkan -> ukuran maksimal per file
+	MaxCount     int              // Jumlah lampiran per prestasi
+	MaxTotalSize int64            // Total ukuran lampiran per prestasi
+}
+
+// LoadAttachmentPolicy membaca kebijakan dari ENV; nilai tidak valid di-log lalu memakai default:
+//   - ATTACHMENT_ALLOWED_TYPES  : "mime=ukuran,..." (default application/pdf=10MB,image/jpeg=5MB,image/png=5MB)
+//   - ATTACHMENT_MAX_COUNT      : jumlah lampiran per prestasi (default 10)
+//   - ATTACHMENT_MAX_TOTAL_SIZE : total ukuran per prestasi (default 50MB)
+func LoadAttachmentPolicy() AttachmentPolicy {
+	policy := AttachmentPolicy{MaxCount: defaultAttachmentMaxCount, MaxTotalSize: defaultAttachmentMaxTotal}
+	policy.MaxSizes, _ = parseAttachmentTypes(defaultAttachmentTypes)
+
+	if raw := os.Getenv("ATTACHMENT_ALLOWED_TYPES"); raw != "" {
+		if sizes, err := parseAttachmentTypes(raw); err == nil {
+			policy.MaxSizes = sizes
+		} else {
+			log.Printf("ATTACHMENT_ALLOWED_TYPES tidak valid (%v), memakai %s", err, defaultAttachmentTypes)
+		}
+	}
+	if raw := os.Getenv("ATTACHMENT_MAX_COUNT"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			policy.MaxCount = n
+		} else {
+			log.Printf("ATTACHMENT_MAX_COUNT tidak valid (%q), memakai %d", raw, defaultAttachmentMaxCount)
+		}
+	}
+	if raw := os.Getenv("ATTACHMENT_MAX_TOTAL_SIZE"); raw != "" {
+		if n, err := parseByteSize(raw); err == nil {
+			policy.MaxTotalSize = n
+		} else {
+			log.Printf("ATTACHMENT_MAX_TOTAL_SIZE tidak valid (%q), memakai %s", raw, formatByteSize(defaultAttachmentMaxTotal))
+		}
+	}
+	return policy
+}
+
+// AttachmentBodyLimit: BodyLimit Fiber yang cukup untuk lampiran terbesar yang diizinkan
+// (tidak pernah lebih kecil dari default Fiber 4MB)
+func AttachmentBodyLimit() int {
+	limit := int64(fiber.DefaultBodyLimit)
+	for _, size := range LoadAttachmentPolicy().MaxSizes {
+		if size+attachmentMultipartOverhead > limit {
+			limit = size + attachmentMultipartOverhead
+		}
+	}
+	return int(limit)
+}
+
+// DetectAttachmentType menentukan tipe MIME dari magic bytes (maks. 512 byte pertama),
+// bukan dari header Content-Type atau ekstensi yang dikirim client
+func DetectAttachmentType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+// CheckFile: Tipe hasil sniffing harus diizinkan dan ukurannya dalam batas tipe tersebut
+func (p AttachmentPolicy) CheckFile(contentType string, size int64) error {
+	maxSize, ok := p.MaxSizes[contentType]
+	if !ok {
+		return response.Validation([]helper.FieldError{{
+			Field:   "file",
+			Message: fmt.Sprintf("tipe file %s tidak didukung, hanya %s", contentType, strings.Join(p.allowedTypes(), ", ")),
+		}})
+	}
+	if size > maxSize {
+		return response.PayloadTooLarge(fmt.Sprintf("Ukuran file melebihi batas %s untuk %s", formatByteSize(maxSize), contentType)).
+			With("max_size", maxSize)
+	}
+	return nil
+}
+
+// CheckQuota: Kuota jumlah & total ukuran lampiran per prestasi, serta menolak file yang sama dua kali
+func (p AttachmentPolicy) CheckQuota(existing []model.Attachment, sha256 string, size int64) error {
+	var total int64
+	for _, a := range existing {
+		if a.SHA256 != "" && a.SHA256 == sha256 {
+			return response.Conflict("File yang sama sudah dilampirkan").With("attachment_id", a.ID)
+		}
+		total += a.Size
+	}
+	if len(existing) >= p.MaxCount {
+		return response.Conflict(fmt.Sprintf("Maksimal %d lampiran per prestasi", p.MaxCount)).With("max_count", p.MaxCount)
+	}
+	if total+size > p.MaxTotalSize {
+		return response.Conflict(fmt.Sprintf("Total ukuran lampiran melebihi kuota %s", formatByteSize(p.MaxTotalSize))).
+			With("max_total_size", p.MaxTotalSize).With("used_size", total)
+	}
+	return nil
+}
+
+func (p AttachmentPolicy) allowedTypes() []string {
+	types := make([]string, 0, len(p.MaxSizes))
+	for t := range p.MaxSizes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// attachmentFileName: Nama file yang aman dengan ekstensi sesuai tipe hasil sniffing
+func attachmentFileName(clientName, contentType string) string {
+	exts := attachmentExtensions[contentType]
+	if len(exts) == 0 {
+		return helper.SanitizeFileName(clientName, "")
+	}
+	return helper.SanitizeFileName(clientName, exts[0], exts...)
+}
+
+// parseAttachmentTypes: "application/pdf=10MB,image/png=5MB" -> map tipe -> byte
+func parseAttachmentTypes(raw string) (map[string]int64, error) {
+	sizes := map[string]int64{}
+	for _, part := range strings.Split(raw, ",") {
+		contentType, size, ok := strings.Cut(strings.TrimSpace(part), "=")
+		contentType = strings.ToLower(strings.TrimSpace(contentType))
+		if !ok || !strings.Contains(contentType, "/") {
+			return nil, fmt.Errorf("format harus mime=ukuran: %q", part)
+		}
+		n, err := parseByteSize(size)
+		if err != nil {
+			return nil, err
+		}
+		sizes[contentType] = n
+	}
+	return sizes, nil
+}
+
+// parseByteSize: "512KB", "10MB", "1GB", atau angka byte (satuan 1024)
+func parseByteSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New("ukuran tidak valid: " + raw)
+	}
+	return n * multiplier, nil
+}
+
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+	return fmt.Sprintf("%dB", n)
+}
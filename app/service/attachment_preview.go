@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/database"
+	"project-uas/preview"
+	"project-uas/storage"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Preview lampiran: worker membuat thumbnail JPEG untuk gambar dan halaman pertama PDF dari lampiran
+// yang sudah lolos pindai malware, disimpan di samping file aslinya (storage.PreviewKey).
+// Detail prestasi menyertakan preview_url (signed URL) agar dosen tidak perlu mengunduh file.
+
+const attachmentPreviewBatchSize = 20
+
+// StartAttachmentPreviewWorker membuat preview lampiran baru sampai ctx dibatalkan
+func StartAttachmentPreviewWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := GenerateAttachmentPreviews(ctx); err != nil {
+				log.Println("preview lampiran:", err)
+			} else if n > 0 {
+				log.Printf("preview lampiran: %d lampiran diproses", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// GenerateAttachmentPreviews menjalankan satu putaran pembuatan preview, per batch urut _id
+// agar lampiran yang gagal tidak menghalangi lampiran setelahnya
+func GenerateAttachmentPreviews(ctx context.Context) (int, error) {
+	if preview.Default == nil {
+		return 0, nil
+	}
+	processed, after := 0, ""
+	for ctx.Err() == nil {
+		docs, err := repository.ListAchievementsPendingPreview(database.MongoDB, preview.FileTypes, after, attachmentPreviewBatchSize)
+		if err != nil {
+			return processed, err
+		}
+		for _, doc := range docs {
+			for _, a := range doc.Attachments {
+				if a.PreviewStatus != "" || !preview.Supports(a.FileType) || checkAttachmentDownloadable(&a) != nil {
+					continue
+				}
+				if err := generateAttachmentPreview(ctx, doc.ID.Hex(), a); err != nil {
+					log.Printf("preview lampiran %s: %v", attachmentStorageKey(&a), err)
+					continue
+				}
+				processed++
+			}
+		}
+		if len(docs) < attachmentPreviewBatchSize {
+			break
+		}
+		after = docs[len(docs)-1].ID.Hex()
+	}
+	return processed, ctx.Err()
+}
+
+// generateAttachmentPreview merender lalu menyimpan preview; file yang tidak bisa dibaca / dirender ditandai
+// failed / unsupported agar tidak diproses ulang setiap putaran
+func generateAttachmentPreview(ctx context.Context, hexID string, a model.Attachment) error {
+	key := attachmentStorageKey(&a)
+	var thumb []byte
+	body, _, err := storage.Default.Open(ctx, key)
+	if err == nil {
+		thumb, err = preview.Default.Generate(ctx, a.FileType, body)
+		body.Close()
+	}
+
+	result := a
+	switch {
+	case errors.Is(err, preview.ErrUnsupported), errors.Is(err, preview.ErrRendererUnavailable):
+		log.Printf("preview lampiran %s: %v", key, err)
+		result.PreviewStatus = model.PreviewStatusUnsupported
+	case err != nil:
+		log.Printf("preview lampiran %s: %v", key, err)
+		result.PreviewStatus = model.PreviewStatusFailed
+	default:
+		result.PreviewKey = storage.PreviewKey(key)
+		if err := storage.Default.Put(ctx, result.PreviewKey, bytes.NewReader(thumb), int64(len(thumb)), preview.ContentType); err != nil {
+			return err
+		}
+		result.PreviewStatus = model.PreviewStatusReady
+	}
+
+	err = repository.UpdateAttachmentPreviewMongo(database.MongoDB, hexID, result, a.StorageKey)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil // Lampiran dihapus / diganti selama diproses
+	}
+	return err
+}
+
+// fillAttachmentPreviewURLs mengisi preview_url (signed URL sementara) untuk lampiran yang preview-nya siap
+func fillAttachmentPreviewURLs(ctx context.Context, detail *model.Achievement) {
+	for i := range detail.Attachments {
+		a := &detail.Attachments[i]
+		if a.PreviewStatus != model.PreviewStatusReady || a.PreviewKey == "" || checkAttachmentDownloadable(a) != nil {
+			continue
+		}
+		url, err := storage.Default.SignedURL(ctx, a.PreviewKey, storage.SignedURLOptions{
+			Expires: attachmentURLTTL, ContentType: preview.ContentType,
+		})
+		if err != nil {
+			log.Printf("preview lampiran %s: gagal membuat signed URL: %v", a.PreviewKey, err)
+			continue
+		}
+		a.PreviewURL = url
+	}
+}
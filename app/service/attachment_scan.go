@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/scanner"
+	"project-uas/storage"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pemindaian malware lampiran:
+//   - Upload / ganti lampiran: file dipindai sebelum disimpan. File terinfeksi disimpan di key karantina
+//     (storage.QuarantineKey) dan tidak bisa diunduh; jika scanner tidak tersedia status menjadi pending.
+//   - Worker memindai ulang lampiran pending dan lampiran lama yang belum pernah dipindai. File yang hilang
+//     atau gagal dibuka maxAttachmentScanAttempts kali ditandai failed dan harus diganti.
+//   - Submit ditolak selama ada lampiran yang belum bersih (lihat CheckAttachmentsScanned).
+
+const (
+	attachmentScanBatchSize   = 20
+	maxAttachmentScanAttempts = 5
+)
+
+// errScannerUnavailable: Batch worker dihentikan, sisanya dicoba di putaran berikutnya
+var errScannerUnavailable = errors.New("scanner malware tidak tersedia")
+
+// scanAttachmentContent memindai isi file dengan scanner.Default.
+// Kegagalan scanner tidak menggagalkan upload, lampiran ditandai pending untuk dipindai ulang.
+func scanAttachmentContent(ctx context.Context, r io.Reader) (model.AttachmentScanStatus, string) {
+	if scanner.Default == nil {
+		return model.ScanStatusSkipped, ""
+	}
+	result, err := scanner.Default.Scan(ctx, r)
+	if err != nil {
+		log.Println("pindai lampiran:", err)
+		return model.ScanStatusPending, ""
+	}
+	if result.Infected {
+		return model.ScanStatusInfected, result.Signature
+	}
+	return model.ScanStatusClean, ""
+}
+
+// CheckAttachmentsScanned: Prestasi hanya bisa disubmit jika semua lampiran sudah dipindai dan bersih
+func CheckAttachmentsScanned(attachments []model.Attachment) error {
+	var blocked []fiber.Map
+	for _, a := range attachments {
+		switch a.ScanStatus {
+		case model.ScanStatusClean, model.ScanStatusSkipped:
+			continue
+		}
+		status := a.ScanStatus
+		if status == "" {
+			status = model.ScanStatusPending
+		}
+		blocked = append(blocked, fiber.Map{"id": a.ID, "file_name": a.FileName, "scan_status": status})
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return response.Conflict("Semua lampiran harus lolos pemindaian malware sebelum disubmit; hapus atau ganti lampiran yang terinfeksi / gagal dibaca").
+		With("attachments", blocked)
+}
+
+// checkAttachmentDownloadable: File terinfeksi / belum dipindai tidak boleh dibuka siapa pun
+func checkAttachmentDownloadable(a *model.Attachment) error {
+	switch a.ScanStatus {
+	case model.ScanStatusClean, model.ScanStatusSkipped:
+		return nil
+	case model.ScanStatusInfected:
+		return response.Forbidden("Lampiran dikarantina karena terdeteksi malware").With("scan_signature", a.ScanSignature)
+	case model.ScanStatusFailed:
+		return response.Conflict("File lampiran tidak bisa dibaca dari storage, ganti lampiran").With("scan_status", a.ScanStatus)
+	}
+	return response.Conflict("Lampiran belum selesai dipindai malware, coba lagi nanti").With("scan_status", model.ScanStatusPending)
+}
+
+// StartAttachmentScanWorker memindai ulang lampiran pending sampai ctx dibatalkan
+func StartAttachmentScanWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := RescanPendingAttachments(ctx); err != nil {
+				log.Println("pindai ulang lampiran:", err)
+			} else if n > 0 {
+				log.Printf("pindai ulang lampiran: %d lampiran dipindai", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RescanPendingAttachments menjalankan satu putaran pemindaian ulang, per batch urut _id
+// agar lampiran yang terus gagal tidak menghalangi lampiran setelahnya
+func RescanPendingAttachments(ctx context.Context) (int, error) {
+	scanned, after := 0, ""
+	for ctx.Err() == nil {
+		docs, err := repository.ListAchievementsPendingScan(database.MongoDB, after, attachmentScanBatchSize)
+		if err != nil {
+			return scanned, err
+		}
+		for _, doc := range docs {
+			for _, a := range doc.Attachments {
+				if a.ScanStatus != "" && a.ScanStatus != model.ScanStatusPending {
+					continue
+				}
+				err := rescanAttachment(ctx, doc.ID.Hex(), a)
+				if errors.Is(err, errScannerUnavailable) {
+					return scanned, err
+				}
+				if err != nil {
+					log.Printf("pindai ulang lampiran %s: %v", attachmentStorageKey(&a), err)
+					continue
+				}
+				scanned++
+			}
+		}
+		if len(docs) < attachmentScanBatchSize {
+			break
+		}
+		after = docs[len(docs)-1].ID.Hex()
+	}
+	return scanned, ctx.Err()
+}
+
+func rescanAttachment(ctx context.Context, hexID string, a model.Attachment) error {
+	key := attachmentStorageKey(&a)
+	body, info, err := storage.Default.Open(ctx, key)
+	if err != nil {
+		return recordAttachmentOpenFailure(hexID, a, err)
+	}
+	status, signature := scanAttachmentContent(ctx, body)
+	body.Close()
+	if status == model.ScanStatusPending {
+		return errScannerUnavailable
+	}
+
+	now := time.Now()
+	scanned := a
+	scanned.ScanStatus, scanned.ScanSignature, scanned.ScannedAt = status, signature, &now
+	// File karantina bisa dipakai bersama seperti file asli: lock shared sampai lampiran menunjuk ke sana
+	err = withStorageKeyLock(key, true, func() error {
+		if status == model.ScanStatusInfected {
+			if scanned.StorageKey, err = quarantineBlob(ctx, key, info); err != nil {
+				return err
+			}
+		}
+		return repository.UpdateAttachmentScanMongo(database.MongoDB, hexID, scanned, a.StorageKey)
+	})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil // Lampiran dihapus / diganti selama dipindai
+	}
+	if err != nil {
+		return err
+	}
+	if status == model.ScanStatusInfected {
+		removeAttachmentBlob(ctx, &a)
+	}
+	return nil
+}
+
+// recordAttachmentOpenFailure menghitung percobaan gagal membuka file. Storage yang sedang bermasalah
+// dicoba lagi di putaran berikutnya; file yang hilang / terus gagal ditandai failed.
+func recordAttachmentOpenFailure(hexID string, a model.Attachment, openErr error) error {
+	failed := a
+	failed.ScanAttempts++
+	failed.ScanStatus = model.ScanStatusPending
+	if errors.Is(openErr, storage.ErrNotFound) || failed.ScanAttempts >= maxAttachmentScanAttempts {
+		now := time.Now()
+		failed.ScanStatus, failed.ScannedAt = model.ScanStatusFailed, &now
+	}
+	err := repository.UpdateAttachmentScanMongo(database.MongoDB, hexID, failed, a.StorageKey)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	return openErr
+}
+
+// quarantineBlob menyalin file ke key karantina; file asli dihapus setelah lampiran menunjuk ke karantina
+func quarantineBlob(ctx context.Context, key string, info *storage.ObjectInfo) (string, error) {
+	body, _, err := storage.Default.Open(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	quarantineKey := storage.QuarantineKey(key)
+	if err := storage.Default.Put(ctx, quarantineKey, body, info.Size, info.ContentType); err != nil {
+		return "", err
+	}
+	return quarantineKey, nil
+}
@@ -0,0 +1,578 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"project-uas/storage"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Masa berlaku signed URL lampiran
+const attachmentURLTTL = 5 * time.Minute
+
+// Prefix FileURL lampiran lama yang disimpan langsung di ./uploads (sebelum storage backend)
+const legacyUploadPrefix = "/uploads/"
+
+// POST /api/v1/achievements/:id/attachments
+// UploadAttachment godoc
+// @Summary      Upload Bukti Dokumen
+// @Description  Upload file bukti prestasi (default PDF/JPEG/PNG, tipe dicek dari isi file) ke storage.
+// @Description  Hanya saat draft / revisi; dibatasi ukuran per tipe, jumlah & total ukuran per prestasi.
+// @Description  File dipindai malware; file terinfeksi dikarantina (scan_status=infected) dan harus dihapus / diganti.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Param        id    path      string  true  "Achievement ID"
+// @Param        file  formData  file    true  "Bukti File (PDF/JPG/PNG)"
+// @Success      200   {object}  fiber.Map{data=model.Attachment}
+// @Failure      400   {object}  fiber.Map
+// @Failure      409   {object}  fiber.Map
+// @Failure      413   {object}  fiber.Map
+// @Failure      422   {object}  fiber.Map
+// @Router       /achievements/{id}/attachments [post]
+func UploadAttachment(c *fiber.Ctx) error {
+	id, _ := uuid.Parse(c.Params("id"))
+	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
+	if err != nil {
+		return response.NotFound("Prestasi tidak ditemukan")
+	}
+	next, err := attachmentEditTransition(c, ref, "diunggah")
+	if err != nil {
+		return err
+	}
+
+	policy := LoadAttachmentPolicy()
+	upload, err := openAttachmentUpload(c, policy)
+	if err != nil {
+		return err
+	}
+	defer upload.file.Close()
+
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return response.Internal("Gagal membaca konten prestasi", err)
+	}
+	if err := policy.CheckQuota(detail.Attachments, upload.sum, upload.size); err != nil {
+		return err
+	}
+
+	if err := ensureBaselineVersion(ref); err != nil {
+		return response.Internal("Gagal menyiapkan versi", err)
+	}
+	attachmentID := uuid.NewString()
+	var attachment model.Attachment
+	err = withStorageKeyLock(upload.key, true, func() error {
+		if attachment, err = upload.store(c, ref, attachmentID); err != nil {
+			return err
+		}
+		// Kuota dicek ulang atomik di filter update: upload paralel tidak bisa melewati batas
+		err := repository.AddAttachmentMongo(database.MongoDB, ref.MongoAchievementID, attachment, policy.MaxCount, policy.MaxTotalSize)
+		if errors.Is(err, repository.ErrAttachmentQuotaExceeded) {
+			return attachmentQuotaRejection(ref, policy, nil, upload.sum, upload.size)
+		}
+		if err != nil {
+			return response.Internal("Gagal menyimpan data lampiran", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if attachment.StorageKey != "" {
+			removeAttachmentBlob(c.Context(), &attachment)
+		}
+		return err
+	}
+	if _, err := recordAchievementVersion(c, ref, model.VersionActionAttachment); err != nil {
+		return err
+	}
+	if err := applyEditTransition(c, ref, next); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"success": true, "message": attachmentSavedMessage(attachment, "File uploaded"), "data": attachment})
+}
+
+// PUT /api/v1/achievements/:id/attachments/:attachmentId
+// ReplaceAttachment godoc
+// @Summary      Ganti Lampiran
+// @Description  Ganti file lampiran dengan file baru; ID & URL lampiran tetap. Aturan tipe, ukuran & kuota sama dengan upload.
+// @Description  Hanya saat draft / revisi; file lama dihapus dari storage jika tidak dipakai prestasi lain.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Param        id            path      string  true  "Achievement ID"
+// @Param        attachmentId  path      string  true  "Attachment ID"
+// @Param        file          formData  file    true  "Bukti File (PDF/JPG/PNG)"
+// @Success      200  {object}  fiber.Map{data=model.Attachment}
+// @Failure      404  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Failure      413  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /achievements/{id}/attachments/{attachmentId} [put]
+func ReplaceAttachment(c *fiber.Ctx) error {
+	ref, detail, old, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+	next, err := attachmentEditTransition(c, ref, "diganti")
+	if err != nil {
+		return err
+	}
+
+	policy := LoadAttachmentPolicy()
+	upload, err := openAttachmentUpload(c, policy)
+	if err != nil {
+		return err
+	}
+	defer upload.file.Close()
+
+	// Lampiran yang diganti tidak ikut dihitung dalam kuota & pengecekan duplikat
+	others := make([]model.Attachment, 0, len(detail.Attachments))
+	for i := range detail.Attachments {
+		if &detail.Attachments[i] != old {
+			others = append(others, detail.Attachments[i])
+		}
+	}
+	if err := policy.CheckQuota(others, upload.sum, upload.size); err != nil {
+		return err
+	}
+
+	if err := ensureBaselineVersion(ref); err != nil {
+		return response.Internal("Gagal menyiapkan versi", err)
+	}
+	// Lampiran lama (sebelum storage backend) belum punya ID; diberi ID saat diganti
+	attachmentID := old.ID
+	if attachmentID == "" {
+		attachmentID = uuid.NewString()
+	}
+	var attachment model.Attachment
+	err = withStorageKeyLock(upload.key, true, func() error {
+		if attachment, err = upload.store(c, ref, attachmentID); err != nil {
+			return err
+		}
+		err := repository.ReplaceAttachmentMongo(database.MongoDB, ref.MongoAchievementID, *old, attachment, policy.MaxTotalSize)
+		if errors.Is(err, repository.ErrAttachmentQuotaExceeded) {
+			return attachmentQuotaRejection(ref, policy, old, upload.sum, upload.size)
+		}
+		if err != nil {
+			return response.Internal("Gagal menyimpan data lampiran", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if attachment.StorageKey != "" && attachment.StorageKey != attachmentStorageKey(old) {
+			removeAttachmentBlob(c.Context(), &attachment)
+		}
+		return err
+	}
+	if attachmentStorageKey(old) != attachment.StorageKey {
+		removeAttachmentBlob(c.Context(), old)
+	}
+	if _, err := recordAchievementVersion(c, ref, model.VersionActionAttachmentReplace); err != nil {
+		return err
+	}
+	if err := applyEditTransition(c, ref, next); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"success": true, "message": attachmentSavedMessage(attachment, "File replaced"), "data": attachment})
+}
+
+// DELETE /api/v1/achievements/:id/attachments/:attachmentId
+// DeleteAttachment godoc
+// @Summary      Hapus Lampiran
+// @Description  Hapus lampiran dari prestasi (hanya saat draft / revisi); file dihapus dari storage jika tidak dipakai prestasi lain
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id            path  string  true  "Achievement ID"
+// @Param        attachmentId  path  string  true  "Attachment ID"
+// @Success      200  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
+// @Router       /achievements/{id}/attachments/{attachmentId} [delete]
+func DeleteAttachment(c *fiber.Ctx) error {
+	ref, _, attachment, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+	next, err := attachmentEditTransition(c, ref, "dihapus")
+	if err != nil {
+		return err
+	}
+
+	if err := ensureBaselineVersion(ref); err != nil {
+		return response.Internal("Gagal menyiapkan versi", err)
+	}
+	err = repository.RemoveAttachmentMongo(database.MongoDB, ref.MongoAchievementID, *attachment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return response.NotFound("Lampiran tidak ditemukan")
+	}
+	if err != nil {
+		return response.Internal("Gagal menghapus data lampiran", err)
+	}
+	removeAttachmentBlob(c.Context(), attachment)
+	if _, err := recordAchievementVersion(c, ref, model.VersionActionAttachmentDelete); err != nil {
+		return err
+	}
+	if err := applyEditTransition(c, ref, next); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "File deleted"})
+}
+
+// GET /api/v1/achievements/:id/attachments/:attachmentId
+// DownloadAttachment godoc
+// @Summary      Unduh Lampiran
+// @Description  Stream file lampiran; hanya untuk yang boleh melihat prestasi (pemilik / dosen wali / admin).
+// @Description  Lampiran yang terinfeksi (403) atau belum dipindai (409) tidak bisa diunduh.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      octet-stream
+// @Param        id            path  string  true  "Achievement ID"
+// @Param        attachmentId  path  string  true  "Attachment ID"
+// @Success      200  {file}    binary
+// @Failure      403  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/{id}/attachments/{attachmentId} [get]
+func DownloadAttachment(c *fiber.Ctx) error {
+	_, _, attachment, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+	if err := checkAttachmentDownloadable(attachment); err != nil {
+		return err
+	}
+
+	body, info, err := storage.Default.Open(c.Context(), attachmentStorageKey(attachment))
+	if errors.Is(err, storage.ErrNotFound) {
+		return response.NotFound("File lampiran tidak ditemukan di storage")
+	}
+	if err != nil {
+		return response.Internal("Gagal membaca file lampiran", err)
+	}
+
+	contentType := attachment.FileType
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+	return sendAttachment(c, body, info.Size, attachment.FileName, contentType)
+}
+
+// GET /api/v1/achievements/:id/attachments/:attachmentId/url
+// GetAttachmentURL godoc
+// @Summary      Signed URL Lampiran
+// @Description  URL unduh sementara (5 menit) yang bisa dibuka tanpa header Authorization
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id            path  string  true  "Achievement ID"
+// @Param        attachmentId  path  string  true  "Attachment ID"
+// @Success      200  {object}  fiber.Map{data=model.AttachmentURLResponse}
+// @Failure      403  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /achievements/{id}/attachments/{attachmentId}/url [get]
+func GetAttachmentURL(c *fiber.Ctx) error {
+	_, _, attachment, err := findAttachment(c)
+	if err != nil {
+		return err
+	}
+	if err := checkAttachmentDownloadable(attachment); err != nil {
+		return err
+	}
+
+	url, err := storage.Default.SignedURL(c.Context(), attachmentStorageKey(attachment), storage.SignedURLOptions{
+		Expires: attachmentURLTTL, FileName: attachment.FileName, ContentType: attachment.FileType,
+	})
+	if err != nil {
+		return response.Internal("Gagal membuat signed URL", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "data": model.AttachmentURLResponse{
+		URL: url, ExpiresAt: time.Now().Add(attachmentURLTTL),
+	}})
+}
+
+// GET /api/v1/files/*  (publik, hanya untuk driver local)
+// ServeSignedFile melayani signed URL dari storage.Local; akses dibatasi oleh signature + expires.
+func ServeSignedFile(c *fiber.Ctx) error {
+	local, ok := storage.Default.(*storage.Local)
+	if !ok {
+		return response.NotFound("Not found")
+	}
+
+	key := c.Params("*")
+	query := map[string][]string{}
+	for _, name := range []string{"expires", "name", "type", "signature"} {
+		query[name] = []string{c.Query(name)}
+	}
+	if err := local.VerifySignedURL(key, query); err != nil {
+		return response.Forbidden(err.Error())
+	}
+
+	body, info, err := local.Open(c.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return response.NotFound("File tidak ditemukan")
+	}
+	if err != nil {
+		return response.Internal("Gagal membaca file", err)
+	}
+	return sendAttachment(c, body, info.Size, c.Query("name"), c.Query("type"))
+}
+
+// findAttachment mencari lampiran :attachmentId di dokumen Mongo prestasi :id.
+// Lampiran lama tanpa ID dicari dengan nama file di FileURL /uploads/<nama>.
+func findAttachment(c *fiber.Ctx) (*model.AchievementReference, *model.Achievement, *model.Attachment, error) {
+	id, _ := uuid.Parse(c.Params("id"))
+	ref, err := repository.GetAchievementReferenceByID(database.DB, id)
+	if err != nil {
+		return nil, nil, nil, response.NotFound("Prestasi tidak ditemukan")
+	}
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return nil, nil, nil, response.NotFound("Konten prestasi tidak ditemukan")
+	}
+
+	attachmentID := c.Params("attachmentId")
+	for i := range detail.Attachments {
+		a := &detail.Attachments[i]
+		if a.ID == attachmentID || (a.ID == "" && a.FileURL == legacyUploadPrefix+attachmentID) {
+			return ref, detail, a, nil
+		}
+	}
+	return nil, nil, nil, response.NotFound("Lampiran tidak ditemukan")
+}
+
+// attachmentQuotaRejection: Alasan penolakan saat filter kuota di Mongo tidak cocok, dihitung dari data terbaru.
+// replaced (opsional) adalah lampiran yang sedang diganti; tidak ikut dihitung dan harus masih ada.
+func attachmentQuotaRejection(ref *model.AchievementReference, policy AttachmentPolicy, replaced *model.Attachment, sum string, size int64) error {
+	detail, err := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
+	if err != nil {
+		return response.NotFound("Konten prestasi tidak ditemukan")
+	}
+	others := make([]model.Attachment, 0, len(detail.Attachments))
+	found := false
+	for _, a := range detail.Attachments {
+		if replaced != nil && a.ID == replaced.ID && a.FileURL == replaced.FileURL {
+			found = true
+			continue
+		}
+		others = append(others, a)
+	}
+	if replaced != nil && !found {
+		return response.NotFound("Lampiran tidak ditemukan")
+	}
+	if err := policy.CheckQuota(others, sum, size); err != nil {
+		return err
+	}
+	return response.Conflict("Lampiran prestasi sudah berubah, muat ulang lalu coba lagi")
+}
+
+// attachmentEditTransition: Mengubah lampiran termasuk aksi edit, hanya saat draft atau sedang direvisi
+// (rejected / changes_requested -> revised). verb dipakai di pesan 409, mis. "diunggah".
+func attachmentEditTransition(c *fiber.Ctx, ref *model.AchievementReference, verb string) (model.AchievementStatus, error) {
+	next, err := NextAchievementStatus(ActionEdit, ref.Status, actorKind(c))
+	if errors.Is(err, ErrIllegalTransition) {
+		return "", response.Conflict("Lampiran hanya bisa "+verb+" saat prestasi masih draft atau sedang direvisi").
+			With("current_status", ref.Status)
+	}
+	if err != nil {
+		return "", workflowError(ref, err)
+	}
+	return next, nil
+}
+
+// attachmentUpload: File multipart "file" yang tipe & ukurannya sudah lolos kebijakan
+type attachmentUpload struct {
+	file        multipart.File
+	fileName    string
+	contentType string
+	size        int64
+	key         string // Key content-addressed di storage
+	sum         string // SHA-256 isi file (hex)
+}
+
+// openAttachmentUpload membaca field "file", menentukan tipe dari magic bytes (header Content-Type
+// & ekstensi dari client diabaikan), mengecek kebijakan tipe/ukuran, lalu menghitung key dari isi file.
+// Pemanggil wajib menutup upload.file.
+func openAttachmentUpload(c *fiber.Ctx, policy AttachmentPolicy) (*attachmentUpload, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, response.BadRequest("File required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, response.BadRequest("File tidak bisa dibaca")
+	}
+
+	upload, err := inspectAttachmentUpload(file, fileHeader, policy)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return upload, nil
+}
+
+func inspectAttachmentUpload(file multipart.File, fileHeader *multipart.FileHeader, policy AttachmentPolicy) (*attachmentUpload, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, response.BadRequest("File tidak bisa dibaca")
+	}
+	if n == 0 {
+		return nil, response.Validation([]helper.FieldError{{Field: "file", Message: "file kosong"}})
+	}
+	contentType := DetectAttachmentType(head[:n])
+	if err := policy.CheckFile(contentType, fileHeader.Size); err != nil {
+		return nil, err
+	}
+
+	// Key dari isi file: upload ulang file yang sama tidak menambah objek baru di storage
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, response.Internal("Gagal membaca file", err)
+	}
+	key, sum, err := storage.ContentKey(file)
+	if err != nil {
+		return nil, response.Internal("Gagal membaca file", err)
+	}
+	return &attachmentUpload{
+		file: file, fileName: fileHeader.Filename, contentType: contentType,
+		size: fileHeader.Size, key: key, sum: sum,
+	}, nil
+}
+
+// store memindai file malware, menyimpannya ke storage (karantina jika terinfeksi),
+// dan menyiapkan data lampiran dengan ID yang diberikan
+func (u *attachmentUpload) store(c *fiber.Ctx, ref *model.AchievementReference, attachmentID string) (model.Attachment, error) {
+	if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+		return model.Attachment{}, response.Internal("Gagal membaca file", err)
+	}
+	status, signature := scanAttachmentContent(c.Context(), u.file)
+	key := u.key
+	if status == model.ScanStatusInfected {
+		key = storage.QuarantineKey(u.key)
+	}
+
+	if _, err := u.file.Seek(0, io.SeekStart); err != nil {
+		return model.Attachment{}, response.Internal("Gagal membaca file", err)
+	}
+	if err := storage.Default.Put(c.Context(), key, u.file, u.size, u.contentType); err != nil {
+		return model.Attachment{}, response.Internal("Gagal menyimpan file", err)
+	}
+
+	now := time.Now()
+	attachment := model.Attachment{
+		ID:            attachmentID,
+		FileName:      attachmentFileName(u.fileName, u.contentType),
+		FileURL:       fmt.Sprintf("/api/v1/achievements/%s/attachments/%s", ref.ID, attachmentID),
+		FileType:      u.contentType,
+		Size:          u.size,
+		SHA256:        u.sum,
+		StorageKey:    key,
+		UploadedAt:    now,
+		ScanStatus:    status,
+		ScanSignature: signature,
+	}
+	if status != model.ScanStatusPending {
+		attachment.ScannedAt = &now
+	}
+	return attachment, nil
+}
+
+// attachmentSavedMessage: Pesan sukses upload / ganti, berbeda jika file masuk karantina
+func attachmentSavedMessage(a model.Attachment, message string) string {
+	if a.ScanStatus == model.ScanStatusInfected {
+		return "File quarantined: malware detected"
+	}
+	return message
+}
+
+// removeAttachmentBlob menghapus file lampiran yang sudah dilepas dari prestasi.
+// Data sudah tersimpan, jadi kegagalan hanya dicatat di log.
+func removeAttachmentBlob(ctx context.Context, a *model.Attachment) {
+	if err := deleteUnreferencedBlob(ctx, a, ""); err != nil {
+		log.Printf("lampiran %s: %v", attachmentStorageKey(a), err)
+	}
+}
+
+// deleteUnreferencedBlob menghapus file lampiran beserta preview-nya. Key content-addressed bisa dipakai
+// bersama, jadi file hanya dihapus jika tidak ada dokumen prestasi lain (termasuk di trash, selain
+// exceptHexID) yang masih memakainya. Snapshot versi tidak dihitung.
+func deleteUnreferencedBlob(ctx context.Context, a *model.Attachment, exceptHexID string) error {
+	if a.StorageKey == "" && !strings.HasPrefix(a.FileURL, legacyUploadPrefix) {
+		return nil // Bukan file di storage
+	}
+	if a.StorageKey == "" {
+		return deleteBlobWithPreview(ctx, attachmentStorageKey(a))
+	}
+	// Lock eksklusif: upload isi yang sama menunggu sampai file selesai dihapus lalu menyimpannya ulang,
+	// atau sudah tercatat di Mongo sebelum referensi dihitung
+	return withStorageKeyLock(a.StorageKey, false, func() error {
+		refs, err := repository.CountAttachmentStorageKeyRefs(database.MongoDB, a.StorageKey, exceptHexID)
+		if err != nil {
+			return fmt.Errorf("gagal mengecek pemakaian file: %w", err)
+		}
+		if refs > 0 {
+			return nil
+		}
+		return deleteBlobWithPreview(ctx, a.StorageKey)
+	})
+}
+
+func deleteBlobWithPreview(ctx context.Context, key string) error {
+	for _, k := range []string{key, storage.PreviewKey(key)} {
+		if err := storage.Default.Delete(ctx, k); err != nil {
+			return fmt.Errorf("gagal menghapus file %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// withStorageKeyLock menjalankan fn di bawah advisory lock key storage lampiran. Upload memegang lock
+// shared dari simpan file sampai lampiran tercatat di Mongo; penghapusan file memegang lock eksklusif.
+// File karantina memakai lock key aslinya.
+func withStorageKeyLock(key string, shared bool, fn func() error) error {
+	var fnErr error
+	err := repository.WithStorageKeyLock(database.DB, strings.TrimPrefix(key, storage.QuarantineKey("")), shared, func() error {
+		fnErr = fn()
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return response.Internal("Gagal mengunci file lampiran", err)
+	}
+	return nil
+}
+
+// attachmentStorageKey: Key storage lampiran; lampiran lama memakai nama file di root ./uploads
+func attachmentStorageKey(a *model.Attachment) string {
+	if a.StorageKey != "" {
+		return a.StorageKey
+	}
+	return strings.TrimPrefix(a.FileURL, legacyUploadPrefix)
+}
+
+func sendAttachment(c *fiber.Ctx, body io.ReadCloser, size int64, fileName, contentType string) error {
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set("X-Content-Type-Options", "nosniff")
+	if fileName != "" {
+		c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	// Body ditutup fasthttp setelah selesai di-stream
+	return c.SendStream(body, int(size))
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
 	"project-uas/helper"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -19,27 +21,28 @@ import (
 // @Produce      json
 // @Param        request body model.LoginRequest true "Email & Password"
 // @Success      200  {object}  model.AuthResponse
+// @Failure      422  {object}  fiber.Map
 // @Router /api/v1/auth/login [post]
 func Login(c *fiber.Ctx) error {
 	var req model.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"status": "fail", "message": "Request body invalid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	// 1. Validasi User
 	user, err := repository.GetUserByUsername(database.DB, req.Username)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"status": "fail", "message": "Invalid credentials"})
+		return response.Unauthorized("Invalid credentials")
 	}
 
 	// 2. Cek Password
 	if !helper.CheckPasswordHash(req.Password, user.PasswordHash) {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"status": "fail", "message": "Invalid credentials"})
+		return response.Unauthorized("Invalid credentials")
 	}
 
 	// 3. Cek Active Status
 	if !user.IsActive {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"status": "fail", "message": "User inactive"})
+		return response.Unauthorized("User inactive")
 	}
 
 	// 4. Ambil Permissions (FR-001 Flow 4)
@@ -48,109 +51,193 @@ func Login(c *fiber.Ctx) error {
 		permissions = []string{}
 	}
 
+	// Setiap login membuat sesi (family refresh token) baru
+	sessionID := uuid.New()
+
 	// Generate Access Token
-accessToken, err := helper.GenerateToken(
-	user.ID.String(),
-	user.RoleID.String(),
-	permissions,
-)
-if err != nil {
-	return c.Status(500).JSON(fiber.Map{"message": "Token generation failed"})
-}
+	accessToken, err := helper.GenerateToken(
+		user.ID.String(),
+		user.RoleID.String(),
+		permissions,
+		sessionID.String(),
+	)
+	if err != nil {
+		return response.Internal("Token generation failed", err)
+	}
 
-// Generate Refresh Token
-refreshToken, err := helper.GenerateRefreshToken(user.ID.String())
-if err != nil {
-	return c.Status(500).JSON(fiber.Map{"message": "Refresh token failed"})
-}
+	// Generate Refresh Token
+	refreshToken, err := issueRefreshToken(c, user.ID, sessionID)
+	if err != nil {
+		return response.Internal("Refresh token failed", err)
+	}
 
-return c.JSON(model.AuthResponse{
-	Status: "success",
-	Data: model.LoginResponseData{
-		Token:        accessToken,
-		RefreshToken: refreshToken,
-		User: model.UserLoginData{
-			ID:          user.ID,
-			Username:    user.Username,
-			FullName:    user.FullName,
-			RoleID:      user.RoleID,
-			Permissions: permissions,
+	return c.JSON(model.AuthResponse{
+		Success: true,
+		Data: model.LoginResponseData{
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+			User: model.UserLoginData{
+				ID:          user.ID,
+				Username:    user.Username,
+				FullName:    user.FullName,
+				RoleID:      user.RoleID,
+				Permissions: permissions,
+			},
 		},
-	},
-})
+	})
 }
 
 // POST /api/v1/auth/refresh
 // RefreshToken godoc
 // @Summary      Refresh Access Token
-// @Description  Mendapatkan token baru menggunakan Refresh Token
+// @Description  Mendapatkan access token baru + refresh token baru (rotasi). Refresh token lama tidak bisa dipakai lagi.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        request body model.RefreshTokenRequest true "Refresh Token"
-// @Success      200  {object}  fiber.Map
+// @Success      200  {object}  model.AuthResponse{data=model.RefreshTokenResponseData}
+// @Failure      401  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router /api/v1/auth/refresh [post]
 func RefreshToken(c *fiber.Ctx) error {
 	var req model.RefreshTokenRequest
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid request",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	claims, err := helper.ValidateRefreshToken(req.RefreshToken)
+	stored, err := repository.GetRefreshTokenByHash(database.DB, helper.HashRefreshToken(req.RefreshToken))
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Invalid refresh token",
-		})
+		return response.Unauthorized("Invalid refresh token")
+	}
+
+	// Reuse detection: token yang sudah pernah dirotasi dipakai lagi,
+	// artinya token kemungkinan bocor -> cabut seluruh family
+	if stored.UsedAt != nil {
+		repository.RevokeRefreshTokenFamily(database.DB, stored.FamilyID)
+		return response.Unauthorized("Refresh token reuse detected, session revoked")
 	}
 
-	userID := claims["user_id"].(string)
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return response.Unauthorized("Refresh token expired or revoked")
+	}
+
+	user, err := repository.GetUserByID(database.DB, stored.UserID)
+	if err != nil {
+		return response.NotFound("User not found")
+	}
+	if !user.IsActive {
+		repository.RevokeRefreshTokenFamily(database.DB, stored.FamilyID)
+		return response.Unauthorized("User inactive")
+	}
 
-	user, err := repository.GetUserByID(database.DB, uuid.MustParse(userID))
+	// Rotasi: token lama ditandai "used", token baru masuk family yang sama
+	newRefreshToken, err := helper.GenerateRefreshToken()
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "User not found",
-		})
+		return response.Internal("Failed generate refresh token", err)
+	}
+	next := newRefreshTokenRecord(c, user.ID, stored.FamilyID, newRefreshToken)
+	rotated, err := repository.RotateRefreshToken(database.DB, stored.ID, next)
+	if err != nil {
+		return response.Internal("Failed rotate refresh token", err)
+	}
+	if !rotated {
+		// Token sudah dipakai oleh request lain di antara pengecekan dan rotasi
+		repository.RevokeRefreshTokenFamily(database.DB, stored.FamilyID)
+		return response.Unauthorized("Refresh token reuse detected, session revoked")
 	}
 
 	permissions, _ := repository.GetPermissionNamesByRoleID(database.DB, user.RoleID)
+	if permissions == nil {
+		permissions = []string{}
+	}
 
 	newAccessToken, err := helper.GenerateToken(
 		user.ID.String(),
 		user.RoleID.String(),
 		permissions,
+		stored.FamilyID.String(),
 	)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed generate token",
-		})
+		return response.Internal("Failed generate token", err)
 	}
 
-	return c.JSON(fiber.Map{
-		"status": "success",
-		"data": fiber.Map{
-			"access_token": newAccessToken,
+	return c.JSON(model.AuthResponse{
+		Success: true,
+		Data: model.RefreshTokenResponseData{
+			AccessToken:  newAccessToken,
+			RefreshToken: newRefreshToken,
 		},
 	})
 }
 
+// issueRefreshToken membuat refresh token baru dan menyimpan hash-nya ke database
+func issueRefreshToken(c *fiber.Ctx, userID, familyID uuid.UUID) (string, error) {
+	token, err := helper.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	if err := repository.CreateRefreshToken(database.DB, newRefreshTokenRecord(c, userID, familyID, token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func newRefreshTokenRecord(c *fiber.Ctx, userID, familyID uuid.UUID, token string) *model.RefreshToken {
+	return &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: helper.HashRefreshToken(token),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+		ExpiresAt: time.Now().Add(helper.RefreshTokenTTL),
+	}
+}
 
 // POST /api/v1/auth/logout
 // Logout godoc
 // @Summary      Logout
-// @Description  Menghapus sesi login (Revoke Token)
+// @Description  Mencabut access token yang sedang dipakai beserta refresh token pada sesi yang sama
 // @Tags         Auth
 // @Security     BearerAuth
 // @Success      200  {object}  fiber.Map
+// @Failure      500  {object}  fiber.Map
 // @Router /api/v1/auth/logout [post]
 func Logout(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{"status": "success", "message": "Logged out successfully"})
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+	jti, _ := c.Locals("jti").(string)
+	expiresAt, _ := c.Locals("token_exp").(time.Time)
+
+	if err := revokeAccessToken(jti, userID, expiresAt); err != nil {
+		return response.Internal("Logout failed", err)
+	}
+
+	sid, _ := c.Locals("session_id").(string)
+	if sessionID, err := uuid.Parse(sid); err == nil {
+		if err := repository.RevokeRefreshTokenFamily(database.DB, sessionID); err != nil {
+			return response.Internal("Logout failed", err)
+		}
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Logged out successfully"})
+}
+
+// POST /api/v1/auth/logout-all
+// LogoutAll godoc
+// @Summary      Logout Semua Sesi
+// @Description  Mencabut semua access token & refresh token milik user yang sedang login (semua device)
+// @Tags         Auth
+// @Security     BearerAuth
+// @Success      200  {object}  fiber.Map
+// @Failure      500  {object}  fiber.Map
+// @Router /api/v1/auth/logout-all [post]
+func LogoutAll(c *fiber.Ctx) error {
+	userID, _ := uuid.Parse(c.Locals("user_id").(string))
+
+	if err := revokeAllUserSessions(userID); err != nil {
+		return response.Internal("Logout failed", err)
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "All sessions logged out"})
 }
 
 // GET /api/v1/auth/profile
@@ -168,11 +255,23 @@ func GetProfile(c *fiber.Ctx) error {
 
 	user, err := repository.GetUserByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"status": "fail", "message": "User not found"})
+		return response.NotFound("User not found")
 	}
 
 	return c.JSON(model.AuthResponse{
-		Status: "success",
+		Success: true,
 		Data:   user,
 	})
-}
\ No newline at end of file
+}
+// GET /.well-known/jwks.json
+// GetJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public key (RS256/EdDSA) untuk verifikasi access token secara offline oleh service lain
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  helper.JWKSet
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(helper.Keys().JWKS())
+}
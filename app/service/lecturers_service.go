@@ -4,8 +4,8 @@ import (
 	"log"
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
-	"strings" 
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,26 +13,37 @@ import (
 
 // GetAllLecturers godoc
 // @Summary      Lihat Semua Dosen
-// @Description  Mendapatkan daftar semua dosen yang terdaftar
+// @Description  Mendapatkan daftar dosen sesuai scope aktor (Admin: semua, Dosen: dirinya sendiri, Mahasiswa: dosen walinya)
 // @Tags         Lecturers
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.Lecturer}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "lecturer_id, department, created_at (prefix - untuk desc)"
+// @Param        department  query  string  false  "Filter departemen"
+// @Success      200  {object}  fiber.Map{data=[]model.Lecturer,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /lecturers [get]
 func GetAllLecturers(c *fiber.Ctx) error {
-	lecturers, err := repository.GetAllLecturers(database.DB)
+	lecturerID, all, err := visibleLecturerID(currentActor(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Gagal mengambil data lecturers",
-		})
+		return response.Internal("Gagal mengambil data aktor", err)
 	}
-
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    lecturers,
-	})
+	if !all && lecturerID == nil {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data dosen")
+	}
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	lecturers, err := repository.GetAllLecturers(database.DB, params, lecturerID)
+	if err != nil {
+		return listError(err, "Gagal mengambil data lecturers")
+	}
+	return listResponse(c, params, lecturers)
 }
 
 // GetLecturerByUserID
@@ -45,22 +56,30 @@ func GetAllLecturers(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Router       /lecturers/{id} [get]
 func GetLecturerByUserID(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID [user_id] tidak valid"})
+		return response.BadRequest("ID [user_id] tidak valid")
 	}
 
 	user, err := repository.GetUserByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "User tidak ditemukan"})
+		return response.NotFound("User tidak ditemukan")
 	}
 
 	lecturer, err := repository.GetLecturerByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Data lecturer tidak ditemukan untuk user ini"})
+		return response.NotFound("Data lecturer tidak ditemukan untuk user ini")
+	}
+	visibleID, all, err := visibleLecturerID(currentActor(c))
+	if err != nil {
+		return response.Internal("Gagal mengambil data aktor", err)
+	}
+	if !all && (visibleID == nil || *visibleID != lecturer.ID) {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data dosen ini")
 	}
 
 	response := fiber.Map{
@@ -85,40 +104,39 @@ func GetLecturerByUserID(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Produce      json
 // @Param        id   path      string  true  "Lecturer/User UUID"
-// @Success      200  {object}  fiber.Map{data=[]model.Student}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor"
+// @Param        sort   query  string  false  "student_id, program_study, academic_year, created_at"
+// @Success      200  {object}  fiber.Map{data=[]model.Student,meta=model.ListMeta,links=model.ListLinks}
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Router       /lecturers/{id}/advisees [get]
 func GetLecturerAdvisees(c *fiber.Ctx) error {
 	lecturerID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "ID lecturer tidak valid",
-		})
+		return response.BadRequest("ID lecturer tidak valid")
 	}
 
-	// Pastikan lecturer ada
-	_, err = repository.GetLecturerByID(database.DB, lecturerID)
+	// Pastikan lecturer ada (param = user_id, advisor_id di students = PK lecturers)
+	lecturer, err := repository.GetLecturerByID(database.DB, lecturerID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Lecturer tidak ditemukan",
-		})
+		return response.NotFound("Lecturer tidak ditemukan")
+	}
+	if !CanViewAdvisees(currentActor(c), lecturer) {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data bimbingan dosen ini")
 	}
 
-	advisees, err := repository.GetAdviseesByLecturerID(database.DB, lecturerID)
+	params, err := parseListParams(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Gagal mengambil advisees",
-		})
+		return response.BadRequest(err.Error())
 	}
-
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    advisees,
-	})
+	advisees, err := repository.GetAdviseesByLecturerID(database.DB, lecturer.ID, params)
+	if err != nil {
+		return listError(err, "Gagal mengambil advisees")
+	}
+	return listResponse(c, params, advisees)
 }
 
 // CreateLecturer
@@ -133,18 +151,12 @@ func GetLecturerAdvisees(c *fiber.Ctx) error {
 // @Success      201  {object}  fiber.Map{data=model.Lecturer}
 // @Failure      400  {object}  fiber.Map
 // @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /lecturers [post]
 func CreateLecturer(c *fiber.Ctx) error {
 	var req model.CreateLecturerRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
-	}
-
-	if req.UserID == uuid.Nil || req.LecturerID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Field wajib: 'user_id' dan 'lecturer_id'",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	lecturer := &model.Lecturer{
@@ -155,15 +167,11 @@ func CreateLecturer(c *fiber.Ctx) error {
 
 	if err := repository.CreateLecturer(database.DB, lecturer); err != nil {
 		log.Println("Error membuat lecturer:", err)
-		if strings.Contains(err.Error(), "duplicate key") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"success": false, "message": "Gagal: User ini sudah terdaftar sebagai dosen atau ID dosen sudah ada.", "error": err.Error(),
-			})
+		if response.IsUniqueViolation(err) {
+			return response.Conflict("Gagal: User ini sudah terdaftar sebagai dosen atau ID dosen sudah ada.")
 		}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal membuat data lecturer", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal membuat data lecturer")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -176,7 +184,7 @@ func CreateLecturer(c *fiber.Ctx) error {
 // UpdateLecturer
 // UpdateLecturer godoc
 // @Summary      Update Profil Dosen
-// @Description  Mengubah data NIP/NIDN atau Departemen Dosen
+// @Description  Mengubah data NIP/NIDN atau Departemen Dosen (Admin, permission lecturer:update)
 // @Tags         Lecturers
 // @Security     BearerAuth
 // @Accept       json
@@ -185,22 +193,24 @@ func CreateLecturer(c *fiber.Ctx) error {
 // @Param        request body model.UpdateLecturerRequest true "Data Update"
 // @Success      200  {object}  fiber.Map{data=model.Lecturer}
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /lecturers/{id} [put]
 func UpdateLecturer(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID [user_id] tidak valid"})
+		return response.BadRequest("ID [user_id] tidak valid")
 	}
 
 	var req model.UpdateLecturerRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	lecturer, err := repository.GetLecturerByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Data lecturer tidak ditemukan"})
+		return response.NotFound("Data lecturer tidak ditemukan")
 	}
 
 	if req.LecturerID != "" {
@@ -211,9 +221,7 @@ func UpdateLecturer(c *fiber.Ctx) error {
 	}
 
 	if err := repository.UpdateLecturer(database.DB, lecturer); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengupdate data lecturer", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal mengupdate data lecturer")
 	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Data lecturer berhasil diupdate", "data": lecturer})
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// Query param yang dipakai layer list (selain itu dianggap filter)
+var reservedListParams = map[string]bool{"page": true, "limit": true, "after": true, "sort": true}
+
+// parseListParams membaca ?page=&limit=&after=&sort=-created_at,name serta filter lain dari query string
+func parseListParams(c *fiber.Ctx) (model.ListParams, error) {
+	p := model.ListParams{Page: 1, Limit: defaultListLimit, After: c.Query("after"), Filters: map[string]string{}}
+
+	if raw := c.Query("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return p, errors.New("parameter 'page' harus angka >= 1")
+		}
+		p.Page = n
+	}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxListLimit {
+			return p, errors.New("parameter 'limit' harus angka 1-" + strconv.Itoa(maxListLimit))
+		}
+		p.Limit = n
+	}
+	if raw := c.Query("sort"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if field == "" {
+				return p, errors.New("parameter 'sort' tidak valid")
+			}
+			p.Sort = append(p.Sort, model.SortField{Field: field, Desc: desc})
+		}
+	}
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if !reservedListParams[string(key)] {
+			p.Filters[string(key)] = string(value)
+		}
+	})
+	return p, nil
+}
+
+// listResponse: Envelope list standar {success, data, meta{total, page, limit, next_cursor}, links}
+func listResponse[T any](c *fiber.Ctx, params model.ListParams, result *model.ListResult[T]) error {
+	return listResponseWith(c, params, result, nil)
+}
+
+// listResponseWith: Seperti listResponse dengan key tambahan di root response (mis. facets)
+func listResponseWith[T any](c *fiber.Ctx, params model.ListParams, result *model.ListResult[T], extra fiber.Map) error {
+	meta := model.ListMeta{Total: result.Total, Limit: params.Limit, NextCursor: result.NextCursor}
+	links := model.ListLinks{Self: c.OriginalURL()}
+
+	if params.After == "" {
+		meta.Page = params.Page
+		if params.Page*params.Limit < result.Total {
+			links.Next = listLink(c, "page", strconv.Itoa(params.Page+1))
+		}
+		if params.Page > 1 {
+			links.Prev = listLink(c, "page", strconv.Itoa(params.Page-1))
+		}
+	} else if result.NextCursor != "" {
+		links.Next = listLink(c, "after", result.NextCursor)
+	}
+
+	response := fiber.Map{"success": true, "data": result.Items, "meta": meta, "links": links}
+	for k, v := range extra {
+		response[k] = v
+	}
+	return c.JSON(response)
+}
+
+// listLink: URL saat ini dengan satu query param diganti (page dan after saling eksklusif)
+func listLink(c *fiber.Ctx, key, value string) string {
+	query, _ := url.ParseQuery(string(c.Context().QueryArgs().QueryString()))
+	query.Del("page")
+	query.Del("after")
+	query.Set(key, value)
+	return c.Path() + "?" + query.Encode()
+}
+
+// listError: 400 untuk parameter list yang tidak valid, selain itu 500
+func listError(err error, message string) error {
+	if errors.Is(err, repository.ErrInvalidListQuery) {
+		return response.BadRequest(err.Error())
+	}
+	return response.Internal(message, err)
+}
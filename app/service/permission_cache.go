@@ -0,0 +1,72 @@
+package service
+
+import (
+	"project-uas/app/repository"
+	"project-uas/database"
+	"project-uas/helper"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cache role -> permission dan status user untuk middleware.
+// Di-invalidate setiap kali role_permissions, roles, permissions atau users.role_id/is_active berubah.
+// TTL membatasi data basi jika perubahan dilakukan dari instance lain.
+const permissionCacheTTL = 5 * time.Minute
+
+var (
+	rolePermissionCache = helper.NewTTLCache[uuid.UUID, []string]()
+	userAuthStateCache  = helper.NewTTLCache[uuid.UUID, UserAuthState]()
+)
+
+// UserAuthState: Data user yang dicek di setiap request
+type UserAuthState struct {
+	RoleID   uuid.UUID
+	IsActive bool
+}
+
+// GetRolePermissions mengembalikan nama permission milik role (dari cache jika ada)
+func GetRolePermissions(roleID uuid.UUID) ([]string, error) {
+	if perms, ok := rolePermissionCache.Get(roleID); ok {
+		return perms, nil
+	}
+
+	perms, err := repository.GetPermissionNamesByRoleID(database.DB, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if perms == nil {
+		perms = []string{}
+	}
+	rolePermissionCache.Set(roleID, perms, permissionCacheTTL)
+	return perms, nil
+}
+
+// GetUserAuthState mengembalikan role & status aktif user saat ini (bukan dari token)
+func GetUserAuthState(userID uuid.UUID) (UserAuthState, error) {
+	if state, ok := userAuthStateCache.Get(userID); ok {
+		return state, nil
+	}
+
+	user, err := repository.GetUserByID(database.DB, userID)
+	if err != nil {
+		return UserAuthState{}, err
+	}
+	state := UserAuthState{RoleID: user.RoleID, IsActive: user.IsActive}
+	userAuthStateCache.Set(userID, state, permissionCacheTTL)
+	return state, nil
+}
+
+func invalidateRolePermissions(roleID uuid.UUID) {
+	rolePermissionCache.Delete(roleID)
+	roleInfoCache.Delete(roleID) // Nama role bisa berubah -> jenis aktor ikut berubah
+}
+
+// invalidateAllRolePermissions dipakai saat permission diubah/dihapus (bisa dimiliki banyak role)
+func invalidateAllRolePermissions() {
+	rolePermissionCache.Clear()
+}
+
+func invalidateUserAuthState(userID uuid.UUID) {
+	userAuthStateCache.Delete(userID)
+}
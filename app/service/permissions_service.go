@@ -3,8 +3,8 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
-	"strings" // Di-import untuk error handling
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -16,17 +16,26 @@ import (
 // @Tags         Permissions
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.Permission}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "resource, action, name (prefix - untuk desc)"
+// @Param        resource  query  string  false  "Filter resource"
+// @Param        action    query  string  false  "Filter action"
+// @Success      200  {object}  fiber.Map{data=[]model.Permission,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /permissions [get]
 func GetAllPermissions(c *fiber.Ctx) error {
-	permissions, err := repository.GetAllPermissions(database.DB)
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	permissions, err := repository.GetAllPermissions(database.DB, params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengambil data permissions", "error": err.Error(),
-		})
+		return listError(err, "Gagal mengambil data permissions")
 	}
-	return c.JSON(fiber.Map{"success": true, "data": permissions})
+	return listResponse(c, params, permissions)
 }
 
 // GetPermissionByID godoc
@@ -43,12 +52,12 @@ func GetAllPermissions(c *fiber.Ctx) error {
 func GetPermissionByID(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	permission, err := repository.GetPermissionByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Permission tidak ditemukan"})
+		return response.NotFound("Permission tidak ditemukan")
 	}
 	return c.JSON(fiber.Map{"success": true, "data": permission})
 }
@@ -65,22 +74,16 @@ func GetPermissionByID(c *fiber.Ctx) error {
 // @Failure      400  {object}  fiber.Map
 // @Failure      409  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /permissions [post]
 func CreatePermission(c *fiber.Ctx) error {
-	// 1. Menggunakan struct request yang baru
+	// 1. Parse & validasi request (name, resource, action wajib)
 	var req model.CreatePermissionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	// 2. Validasi diubah: +resource, +action (NOT NULL)
-	if req.Name == "" || req.Resource == "" || req.Action == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false, "message": "Field 'name', 'resource', dan 'action' wajib diisi",
-		})
-	}
-
-	// 3. Model diisi dengan data baru
+	// 2. Model diisi dengan data baru
 	permission := &model.Permission{
 		Name:        req.Name,
 		Resource:    req.Resource,    
@@ -88,17 +91,13 @@ func CreatePermission(c *fiber.Ctx) error {
 		Description: req.Description,
 	}
 
-	// 4. Panggil repository 
+	// 3. Panggil repository 
 	if err := repository.CreatePermission(database.DB, permission); err != nil {
 		// Cek error duplikat 'name'
-		if strings.Contains(err.Error(), "duplicate key") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"success": false, "message": "Gagal: Nama permission sudah ada.", "error": err.Error(),
-			})
+		if response.IsUniqueViolation(err) {
+			return response.Conflict("Gagal: Nama permission sudah ada.")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal menambah permission", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal menambah permission")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Permission berhasil ditambahkan", "data": permission})
@@ -118,50 +117,46 @@ func CreatePermission(c *fiber.Ctx) error {
 // @Failure      404  {object}  fiber.Map
 // @Failure      409  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /permissions/{id} [put]
 func UpdatePermission(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
-	// 1. Menggunakan struct request 
+	// 1. Parse & validasi request (name, resource, action wajib)
 	var req model.UpdatePermissionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
-	}
-
-	// 2. Validasi diubah: +resource, +action (NOT NULL)
-	if req.Name == "" || req.Resource == "" || req.Action == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false, "message": "Field 'name', 'resource', dan 'action' wajib diisi",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	// 3. Ambil data lama
+	// 2. Ambil data lama
 	permission, err := repository.GetPermissionByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Permission tidak ditemukan"})
+		return response.NotFound("Permission tidak ditemukan")
+	}
+
+	// Permission RBAC inti tidak boleh di-rename (akan memutus akses admin)
+	if isProtectedPermission(permission.Name) && req.Name != permission.Name {
+		return response.Conflict("Permission sistem tidak boleh di-rename")
 	}
 
-	// 4. Update data
+	// 3. Update data
 	permission.Name = req.Name
 	permission.Resource = req.Resource 
 	permission.Action = req.Action     
 	permission.Description = req.Description
 
-	// 5. Panggil repository 
+	// 4. Panggil repository 
 	if err := repository.UpdatePermission(database.DB, permission); err != nil {
 		// Cek error duplikat 'name'
-		if strings.Contains(err.Error(), "duplicate key") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-				"success": false, "message": "Gagal: Nama permission sudah ada.", "error": err.Error(),
-			})
+		if response.IsUniqueViolation(err) {
+			return response.Conflict("Gagal: Nama permission sudah ada.")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengupdate permission", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal mengupdate permission")
 	}
+	invalidateAllRolePermissions()
 
 	return c.JSON(fiber.Map{"success": true, "message": "Permission berhasil diupdate", "data": permission})
 }
@@ -175,19 +170,24 @@ func UpdatePermission(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "Permission ID (UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /permissions/{id} [delete]
 func DeletePermission(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
+	}
+
+	// Permission RBAC inti tidak boleh dihapus
+	if permission, err := repository.GetPermissionByID(database.DB, id); err == nil && isProtectedPermission(permission.Name) {
+		return response.Conflict("Permission sistem tidak boleh dihapus")
 	}
 
 	if err := repository.DeletePermission(database.DB, id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal menghapus permission", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal menghapus permission")
 	}
+	invalidateAllRolePermissions()
 
 	return c.JSON(fiber.Map{"success": true, "message": "Permission berhasil dihapus"})
 }
\ No newline at end of file
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/database"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Safeguard RBAC: Permission inti (protectedPermissions) harus selalu dimiliki minimal satu role,
+// dan caller tidak boleh mencabut permission inti dari role miliknya sendiri (agar tidak terkunci dari panel admin).
+
+// protectedPermissions: Permission RBAC inti; tanpa salah satunya administrasi role / user tidak bisa dipulihkan
+var protectedPermissions = []string{
+	model.PermissionRoleManage,
+	model.PermissionPermissionManage,
+	model.PermissionUserAssignRole,
+}
+
+// guardAdminRoleLoss mengembalikan pesan penolakan jika roleID akan kehilangan salah satu permissions
+// (karena permission dicabut atau role dihapus) dan hal itu melanggar safeguard.
+// String kosong berarti aman.
+func guardAdminRoleLoss(c *fiber.Ctx, roleID uuid.UUID, permissions ...string) (string, error) {
+	for _, name := range permissions {
+		has, err := repository.RoleHasPermission(database.DB, roleID, name)
+		if err != nil {
+			return "", err
+		}
+		if !has {
+			continue
+		}
+
+		if callerRoleID, _ := c.Locals("role_id").(string); callerRoleID == roleID.String() {
+			return fmt.Sprintf("Tidak bisa mencabut akses admin (%s) dari role Anda sendiri", name), nil
+		}
+
+		count, err := repository.CountRolesWithPermission(database.DB, name)
+		if err != nil {
+			return "", err
+		}
+		if count <= 1 {
+			return fmt.Sprintf("Role ini adalah satu-satunya role dengan %s, tidak bisa dihapus / dicabut", name), nil
+		}
+	}
+	return "", nil
+}
+
+// guardSelfRoleChange mencegah caller memindahkan dirinya sendiri ke role yang tidak memiliki
+// permission inti yang dimiliki role lamanya
+func guardSelfRoleChange(c *fiber.Ctx, targetUserID, newRoleID uuid.UUID) (string, error) {
+	if callerID, _ := c.Locals("user_id").(string); callerID != targetUserID.String() {
+		return "", nil
+	}
+
+	callerRoleID, err := uuid.Parse(c.Locals("role_id").(string))
+	if err != nil || callerRoleID == newRoleID {
+		return "", nil
+	}
+
+	for _, name := range protectedPermissions {
+		had, err := repository.RoleHasPermission(database.DB, callerRoleID, name)
+		if err != nil {
+			return "", err
+		}
+		if !had {
+			continue
+		}
+		keeps, err := repository.RoleHasPermission(database.DB, newRoleID, name)
+		if err != nil {
+			return "", err
+		}
+		if !keeps {
+			return fmt.Sprintf("Tidak bisa mengganti role Anda sendiri ke role tanpa akses admin (%s)", name), nil
+		}
+	}
+	return "", nil
+}
+
+// isProtectedPermission: Permission RBAC inti tidak boleh dihapus / di-rename
+func isProtectedPermission(name string) bool {
+	for _, p := range protectedPermissions {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
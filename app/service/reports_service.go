@@ -3,6 +3,7 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
 
 	"github.com/gofiber/fiber/v2"
@@ -59,6 +60,7 @@ func GetSystemStatistics(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  fiber.Map{data=model.StudentReportResponse}
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Router       /reports/student/{id} [get]
 func GetStudentReport(c *fiber.Ctx) error {
@@ -66,13 +68,16 @@ func GetStudentReport(c *fiber.Ctx) error {
 	// Asumsi disini adalah UserID (dari login).
 	paramID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	// 1. Ambil Profil Mahasiswa (Postgres)
 	student, err := repository.GetStudentByID(database.DB, paramID) // Menggunakan func yg ada di students_repository
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Mahasiswa tidak ditemukan"})
+		return response.NotFound("Mahasiswa tidak ditemukan")
+	}
+	if !CanViewStudentData(currentActor(c), student) {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data mahasiswa ini")
 	}
 	// Ambil nama user juga
 	user, _ := repository.GetUserByID(database.DB, student.UserID)
@@ -83,23 +88,28 @@ func GetStudentReport(c *fiber.Ctx) error {
 		refs = []model.AchievementReference{}
 	}
 
-	// 3. Ambil Total Poin (Mongo) - Menggunakan UUID student (string)
-	totalPoints, _ := repository.SumStudentPoints(database.MongoDB, student.ID.String())
+	// 3. Buat List Prestasi Ringkas (Gabung Status Postgres + Detail Mongo, satu query $in)
+	mongoIDs := make([]string, len(refs))
+	for i, ref := range refs {
+		mongoIDs[i] = ref.MongoAchievementID
+	}
+	details, _ := repository.GetAchievementsMongoByIDs(database.MongoDB, mongoIDs)
 
-	// 4. Buat List Prestasi Ringkas (Gabung Status Postgres + Detail Mongo)
+	// Total poin hanya dari prestasi yang sudah diverifikasi (status ada di Postgres)
+	totalPoints := 0
 	var simpleList []model.SimpleAchievementView
 	for _, ref := range refs {
-		// Ambil detail judul & points dari mongo satu per satu (bisa dioptimasi dengan query $in)
-		detail, _ := repository.GetAchievementMongoByID(database.MongoDB, ref.MongoAchievementID)
-		
 		title := "Unknown Title"
 		aType := "Unknown"
 		points := 0
-		if detail != nil {
+		if detail, ok := details[ref.MongoAchievementID]; ok {
 			title = detail.Title
 			aType = detail.AchievementType
 			points = detail.Points
 		}
+		if ref.Status == model.StatusVerified {
+			totalPoints += points
+		}
 
 		simpleList = append(simpleList, model.SimpleAchievementView{
 			ID:              ref.ID,
@@ -110,7 +120,7 @@ func GetStudentReport(c *fiber.Ctx) error {
 		})
 	}
 
-	// 5. Response Final
+	// 4. Response Final
 	response := model.StudentReportResponse{
 		StudentID:         student.StudentID, // NIM
 		FullName:          user.FullName,
@@ -3,6 +3,7 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
 
 	"github.com/gofiber/fiber/v2"
@@ -23,14 +24,12 @@ import (
 func GetPermissionsByRoleID(c *fiber.Ctx) error {
 	roleID, err := uuid.Parse(c.Params("role_id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Role ID tidak valid"})
+		return response.BadRequest("Role ID tidak valid")
 	}
 
 	permissions, err := repository.GetPermissionsByRoleID(database.DB, roleID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengambil permissions", "error": err.Error(),
-		})
+		return response.Internal("Gagal mengambil permissions", err)
 	}
 	return c.JSON(fiber.Map{"success": true, "data": permissions})
 }
@@ -46,11 +45,12 @@ func GetPermissionsByRoleID(c *fiber.Ctx) error {
 // @Success      201  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /roles/permissions [post]
 func AssignPermissionToRole(c *fiber.Ctx) error {
 	var req model.AssignPermissionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	rp := &model.RolePermission{
@@ -59,10 +59,9 @@ func AssignPermissionToRole(c *fiber.Ctx) error {
 	}
 
 	if err := repository.AssignPermissionToRole(database.DB, rp); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal assign permission", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal assign permission")
 	}
+	invalidateRolePermissions(rp.RoleID)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Permission berhasil di-assign"})
 }
@@ -77,24 +76,38 @@ func AssignPermissionToRole(c *fiber.Ctx) error {
 // @Param        permission_id  path      string  true  "Permission ID (UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /roles/{role_id}/permissions/{permission_id} [delete]
 func RevokePermissionFromRole(c *fiber.Ctx) error {
 	roleID, err := uuid.Parse(c.Params("role_id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Role ID tidak valid"})
+		return response.BadRequest("Role ID tidak valid")
 	}
 
 	permissionID, err := uuid.Parse(c.Params("permission_id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Permission ID tidak valid"})
+		return response.BadRequest("Permission ID tidak valid")
+	}
+
+	// Safeguard: jangan sampai role admin terakhir / role caller kehilangan permission RBAC inti
+	permission, err := repository.GetPermissionByID(database.DB, permissionID)
+	if err != nil {
+		return response.NotFound("Permission tidak ditemukan")
+	}
+	if isProtectedPermission(permission.Name) {
+		if msg, err := guardAdminRoleLoss(c, roleID, permission.Name); err != nil {
+			return response.Internal("Gagal memeriksa role admin", err)
+		} else if msg != "" {
+			return response.Conflict(msg)
+		}
 	}
 
 	if err := repository.RevokePermissionFromRole(database.DB, roleID, permissionID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal revoke permission", "error": err.Error(),
-		})
+		return response.Internal("Gagal revoke permission", err)
 	}
+	invalidateRolePermissions(roleID)
 
 	return c.JSON(fiber.Map{"success": true, "message": "Permission berhasil di-revoke"})
 }
\ No newline at end of file
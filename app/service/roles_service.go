@@ -3,6 +3,7 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,17 +16,25 @@ import (
 // @Tags         Roles
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.Role}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "name, created_at (prefix - untuk desc)"
+// @Param        name   query  string  false  "Cari nama role (mengandung)"
+// @Success      200  {object}  fiber.Map{data=[]model.Role,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /roles [get]
 func GetAllRoles(c *fiber.Ctx) error {
-	roles, err := repository.GetAllRoles(database.DB)
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	roles, err := repository.GetAllRoles(database.DB, params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengambil data roles", "error": err.Error(),
-		})
+		return listError(err, "Gagal mengambil data roles")
 	}
-	return c.JSON(fiber.Map{"success": true, "data": roles})
+	return listResponse(c, params, roles)
 }
 
 // GetRoleByID godoc
@@ -42,12 +51,12 @@ func GetAllRoles(c *fiber.Ctx) error {
 func GetRoleByID(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	role, err := repository.GetRoleByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Role tidak ditemukan"})
+		return response.NotFound("Role tidak ditemukan")
 	}
 	return c.JSON(fiber.Map{"success": true, "data": role})
 }
@@ -63,15 +72,12 @@ func GetRoleByID(c *fiber.Ctx) error {
 // @Success      201  {object}  fiber.Map{data=model.Role}
 // @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /roles [post]
 func CreateRole(c *fiber.Ctx) error {
 	var req model.CreateRoleRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
-	}
-
-	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Field 'name' wajib diisi"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	role := &model.Role{
@@ -80,9 +86,7 @@ func CreateRole(c *fiber.Ctx) error {
 	}
 
 	if err := repository.CreateRole(database.DB, role); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal menambah role", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal menambah role")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Role berhasil ditambahkan", "data": role})
@@ -101,26 +105,23 @@ func CreateRole(c *fiber.Ctx) error {
 // @Failure      400  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /roles/{id} [put]
 func UpdateRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	var req model.UpdateRoleRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
-	}
-
-	if req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Field 'name' wajib diisi"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	// Cek apakah role ada
 	role, err := repository.GetRoleByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Role tidak ditemukan"})
+		return response.NotFound("Role tidak ditemukan")
 	}
 
 	// Update data
@@ -128,10 +129,9 @@ func UpdateRole(c *fiber.Ctx) error {
 	role.Description = req.Description
 
 	if err := repository.UpdateRole(database.DB, role); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengupdate role", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal mengupdate role")
 	}
+	invalidateRolePermissions(role.ID)
 
 	return c.JSON(fiber.Map{"success": true, "message": "Role berhasil diupdate", "data": role})
 }
@@ -145,19 +145,26 @@ func UpdateRole(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "Role ID (UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      409  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /roles/{id} [delete]
 func DeleteRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
+	}
+
+	// Safeguard: role admin terakhir / role milik caller tidak boleh dihapus
+	if msg, err := guardAdminRoleLoss(c, id, protectedPermissions...); err != nil {
+		return response.Internal("Gagal memeriksa role admin", err)
+	} else if msg != "" {
+		return response.Conflict(msg)
 	}
 
 	if err := repository.DeleteRole(database.DB, id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal menghapus role", "error": err.Error(),
-		})
+		return response.FromDB(err, "Gagal menghapus role")
 	}
+	invalidateRolePermissions(id)
 
 	return c.JSON(fiber.Map{"success": true, "message": "Role berhasil dihapus"})
 }
\ No newline at end of file
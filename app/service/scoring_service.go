@@ -0,0 +1,302 @@
+package service
+
+import (
+	"log"
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Cache aturan aktif per tipe prestasi, di-clear setiap aturan berubah
+const scoringRuleCacheTTL = 5 * time.Minute
+
+var scoringRuleCache = helper.NewTTLCache[string, []model.ScoringRule]()
+
+// MatchScoringRule memilih aturan untuk details prestasi: semua kondisi non-nil harus cocok,
+// lalu dipilih priority tertinggi, kemudian aturan dengan kondisi terbanyak (paling spesifik).
+// nil jika tidak ada aturan yang cocok.
+func MatchScoringRule(rules []model.ScoringRule, details map[string]interface{}) *model.ScoringRule {
+	level, _ := details["level"].(string)
+	participation, _ := details["participation"].(string)
+	if participation == "" {
+		participation = model.ParticipationIndividual
+	}
+	rank, hasRank := details["rank"].(float64)
+
+	var best *model.ScoringRule
+	bestSpecificity := -1
+	for i := range rules {
+		r := &rules[i]
+		specificity := 0
+		if r.Level != nil {
+			if *r.Level != level {
+				continue
+			}
+			specificity++
+		}
+		if r.Participation != nil {
+			if *r.Participation != participation {
+				continue
+			}
+			specificity++
+		}
+		if r.RankMin != nil || r.RankMax != nil {
+			if !hasRank || (r.RankMin != nil && rank < float64(*r.RankMin)) || (r.RankMax != nil && rank > float64(*r.RankMax)) {
+				continue
+			}
+			specificity++
+		}
+
+		if best == nil || r.Priority > best.Priority || (r.Priority == best.Priority && specificity > bestSpecificity) {
+			best, bestSpecificity = r, specificity
+		}
+	}
+	return best
+}
+
+func activeScoringRules(achievementType string) ([]model.ScoringRule, error) {
+	if rules, ok := scoringRuleCache.Get(achievementType); ok {
+		return rules, nil
+	}
+	rules, err := repository.GetActiveScoringRules(database.DB, achievementType)
+	if err != nil {
+		return nil, err
+	}
+	scoringRuleCache.Set(achievementType, rules, scoringRuleCacheTTL)
+	return rules, nil
+}
+
+// scoreAchievement menghitung poin prestasi dari aturan aktif (tanpa penyesuaian dosen)
+func scoreAchievement(achievementType string, details map[string]interface{}) (model.AchievementScoring, error) {
+	rules, err := activeScoringRules(achievementType)
+	if err != nil {
+		return model.AchievementScoring{}, err
+	}
+	scoring := model.AchievementScoring{CalculatedAt: time.Now()}
+	if rule := MatchScoringRule(rules, details); rule != nil {
+		id := rule.ID
+		scoring.RuleID = &id
+		scoring.ComputedPoints = rule.Points
+	}
+	return scoring, nil
+}
+
+// effectivePoints: Penyesuaian dosen menggantikan hasil aturan
+func effectivePoints(s model.AchievementScoring) int {
+	if s.Adjustment != nil {
+		return s.Adjustment.Points
+	}
+	return s.ComputedPoints
+}
+
+// RecalculateAchievementPoints menghitung ulang poin semua prestasi satu tipe (kosong = semua tipe).
+// Penyesuaian dosen dipertahankan. Mengembalikan jumlah dokumen yang poinnya berubah.
+func RecalculateAchievementPoints(achievementType string) (int, error) {
+	docs, err := repository.ListAchievementsForScoring(database.MongoDB, achievementType)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, doc := range docs {
+		scoring, err := scoreAchievement(doc.AchievementType, doc.Details)
+		if err != nil {
+			return updated, err
+		}
+		if doc.Scoring != nil {
+			scoring.Adjustment = doc.Scoring.Adjustment
+			if sameRule(doc.Scoring.RuleID, scoring.RuleID) && doc.Scoring.ComputedPoints == scoring.ComputedPoints &&
+				doc.Points == effectivePoints(scoring) {
+				continue
+			}
+		}
+		if err := repository.UpdateAchievementScoring(database.MongoDB, doc.ID.Hex(), effectivePoints(scoring), scoring); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func sameRule(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// recalculateAfterRuleChange: Hitung ulang tipe yang terdampak perubahan aturan
+func recalculateAfterRuleChange(types ...string) int {
+	scoringRuleCache.Clear()
+	total := 0
+	seen := map[string]bool{}
+	for _, t := range types {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		n, err := RecalculateAchievementPoints(t)
+		if err != nil {
+			log.Printf("Gagal menghitung ulang poin tipe %s: %v", t, err)
+		}
+		total += n
+	}
+	return total
+}
+
+// validateScoringRuleRequest: Validasi antar-field yang tidak bisa lewat tag `validate`
+func validateScoringRuleRequest(req model.ScoringRuleRequest) []helper.FieldError {
+	if req.RankMin != nil && req.RankMax != nil && *req.RankMax < *req.RankMin {
+		return []helper.FieldError{{Field: "rank_max", Message: "tidak boleh lebih kecil dari rank_min"}}
+	}
+	return nil
+}
+
+// GetAllScoringRules godoc
+// @Summary      Lihat Aturan Poin
+// @Description  Daftar aturan perhitungan poin prestasi
+// @Tags         Scoring Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "achievement_type, points, priority, created_at (prefix - untuk desc)"
+// @Param        achievement_type  query  string  false  "Filter tipe prestasi (pisahkan koma)"
+// @Param        is_active         query  bool    false  "Filter status aktif"
+// @Success      200  {object}  fiber.Map{data=[]model.ScoringRule,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
+// @Failure      500  {object}  fiber.Map
+// @Router       /scoring-rules [get]
+func GetAllScoringRules(c *fiber.Ctx) error {
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	rules, err := repository.GetAllScoringRules(database.DB, params)
+	if err != nil {
+		return listError(err, "Gagal mengambil data aturan poin")
+	}
+	return listResponse(c, params, rules)
+}
+
+// CreateScoringRule godoc
+// @Summary      Tambah Aturan Poin
+// @Description  Menambah aturan poin (Admin). Poin prestasi tipe terkait langsung dihitung ulang.
+// @Tags         Scoring Rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body model.ScoringRuleRequest true "Data Aturan"
+// @Success      201  {object}  fiber.Map{data=model.ScoringRule}
+// @Failure      400  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /scoring-rules [post]
+func CreateScoringRule(c *fiber.Ctx) error {
+	var req model.ScoringRuleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if errs := validateScoringRuleRequest(req); len(errs) > 0 {
+		return response.Validation(errs)
+	}
+
+	rule := &model.ScoringRule{IsActive: true}
+	applyScoringRuleRequest(rule, req)
+	if err := repository.CreateScoringRule(database.DB, rule); err != nil {
+		return response.FromDB(err, "Gagal menambah aturan poin")
+	}
+	recalculated := recalculateAfterRuleChange(rule.AchievementType)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true, "message": "Aturan poin berhasil ditambahkan", "data": rule, "recalculated": recalculated,
+	})
+}
+
+// UpdateScoringRule godoc
+// @Summary      Update Aturan Poin
+// @Description  Mengubah aturan poin (Admin). Poin prestasi tipe terkait langsung dihitung ulang.
+// @Tags         Scoring Rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Rule ID (UUID)"
+// @Param        request body model.ScoringRuleRequest true "Data Aturan"
+// @Success      200  {object}  fiber.Map{data=model.ScoringRule}
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
+// @Router       /scoring-rules/{id} [put]
+func UpdateScoringRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest("ID tidak valid")
+	}
+	var req model.ScoringRuleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if errs := validateScoringRuleRequest(req); len(errs) > 0 {
+		return response.Validation(errs)
+	}
+
+	rule, err := repository.GetScoringRuleByID(database.DB, id)
+	if err != nil {
+		return response.NotFound("Aturan poin tidak ditemukan")
+	}
+	oldType := rule.AchievementType
+	applyScoringRuleRequest(rule, req)
+	if err := repository.UpdateScoringRule(database.DB, rule); err != nil {
+		return response.FromDB(err, "Gagal mengupdate aturan poin")
+	}
+	recalculated := recalculateAfterRuleChange(oldType, rule.AchievementType)
+
+	return c.JSON(fiber.Map{"success": true, "message": "Aturan poin berhasil diupdate", "data": rule, "recalculated": recalculated})
+}
+
+// DeleteScoringRule godoc
+// @Summary      Hapus Aturan Poin
+// @Description  Menghapus aturan poin (Admin). Poin prestasi tipe terkait langsung dihitung ulang.
+// @Tags         Scoring Rules
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Rule ID (UUID)"
+// @Success      200  {object}  fiber.Map
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /scoring-rules/{id} [delete]
+func DeleteScoringRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest("ID tidak valid")
+	}
+	rule, err := repository.GetScoringRuleByID(database.DB, id)
+	if err != nil {
+		return response.NotFound("Aturan poin tidak ditemukan")
+	}
+	if err := repository.DeleteScoringRule(database.DB, id); err != nil {
+		return response.FromDB(err, "Gagal menghapus aturan poin")
+	}
+	recalculated := recalculateAfterRuleChange(rule.AchievementType)
+
+	return c.JSON(fiber.Map{"success": true, "message": "Aturan poin berhasil dihapus", "recalculated": recalculated})
+}
+
+func applyScoringRuleRequest(rule *model.ScoringRule, req model.ScoringRuleRequest) {
+	rule.AchievementType = req.AchievementType
+	rule.Level = req.Level
+	rule.RankMin = req.RankMin
+	rule.RankMax = req.RankMax
+	rule.Participation = req.Participation
+	rule.Points = req.Points
+	rule.Priority = req.Priority
+	rule.Description = req.Description
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
@@ -3,9 +3,15 @@ package service_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"project-uas/app/model"
+	"project-uas/app/response"
+	"project-uas/app/service"
+	"project-uas/helper"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -117,3 +123,336 @@ func TestCreateAchievementService_Invalid(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+/* ================= TEST TOKEN DENYLIST ===================== */
+
+func TestIsRevokedByLogoutAll(t *testing.T) {
+	revokedBefore := time.Date(2026, 1, 2, 10, 0, 0, 500000000, time.UTC) // logout-all di tengah detik
+
+	before := helper.TokenIssuedAt(jwt.MapClaims{"iat": float64(revokedBefore.Unix()), "iat_us": float64(revokedBefore.Add(-time.Millisecond).UnixMicro())})
+	if !service.IsRevokedByLogoutAll(before, revokedBefore) {
+		t.Fatal("token yang terbit sebelum logout-all seharusnya dicabut")
+	}
+
+	// Login ulang di detik yang sama setelah logout-all
+	after := helper.TokenIssuedAt(jwt.MapClaims{"iat": float64(revokedBefore.Unix()), "iat_us": float64(revokedBefore.Add(time.Millisecond).UnixMicro())})
+	if service.IsRevokedByLogoutAll(after, revokedBefore) {
+		t.Fatal("token yang terbit setelah logout-all (detik yang sama) seharusnya tetap valid")
+	}
+
+	// Token lama tanpa iat_us: dibandingkan per detik
+	legacy := helper.TokenIssuedAt(jwt.MapClaims{"iat": float64(revokedBefore.Unix())})
+	if !service.IsRevokedByLogoutAll(legacy, revokedBefore) {
+		t.Fatal("token lama yang terbit sebelum logout-all seharusnya dicabut")
+	}
+
+	if service.IsRevokedByLogoutAll(before, time.Time{}) {
+		t.Fatal("tanpa logout-all tidak ada token yang dicabut")
+	}
+}
+
+func TestLogoutAllKeepsFreshLogin(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	issuedAt := func(token string) time.Time {
+		claims, err := helper.Keys().Parse(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return helper.TokenIssuedAt(claims)
+	}
+
+	oldToken, err := helper.GenerateToken(uuid.NewString(), uuid.NewString(), nil, uuid.NewString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	revokedBefore := time.Now().Truncate(time.Microsecond) // logout-all
+	time.Sleep(time.Millisecond)
+	freshToken, _ := helper.GenerateToken(uuid.NewString(), uuid.NewString(), nil, uuid.NewString())
+
+	if !service.IsRevokedByLogoutAll(issuedAt(oldToken), revokedBefore) {
+		t.Fatal("token sebelum logout-all seharusnya dicabut")
+	}
+	if service.IsRevokedByLogoutAll(issuedAt(freshToken), revokedBefore) {
+		t.Fatal("login ulang setelah logout-all seharusnya tetap valid")
+	}
+}
+
+/* ====================== TEST ACTOR SCOPE ==================== */
+
+func TestCanViewStudentData(t *testing.T) {
+	studentPK := uuid.New()
+	lecturerPK := uuid.New()
+	student := &model.Student{ID: studentPK, AdvisorID: &lecturerPK}
+
+	otherPK := uuid.New()
+	cases := []struct {
+		name  string
+		actor *model.Actor
+		want  bool
+	}{
+		{"admin", &model.Actor{Kind: model.ActorAdmin}, true},
+		{"owner", &model.Actor{Kind: model.ActorStudent, StudentID: &studentPK}, true},
+		{"other student", &model.Actor{Kind: model.ActorStudent, StudentID: &otherPK}, false},
+		{"advisor", &model.Actor{Kind: model.ActorLecturer, LecturerID: &lecturerPK}, true},
+		{"other lecturer", &model.Actor{Kind: model.ActorLecturer, LecturerID: &otherPK}, false},
+		{"custom role", &model.Actor{Kind: model.ActorCustom}, false},
+		{"no actor", nil, false},
+	}
+
+	for _, tc := range cases {
+		if got := service.CanViewStudentData(tc.actor, student); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestStudentListScopeFor(t *testing.T) {
+	studentPK := uuid.New()
+	lecturerPK := uuid.New()
+
+	if scope, ok := service.StudentListScopeFor(&model.Actor{Kind: model.ActorAdmin}); !ok || scope.StudentID != nil || scope.AdvisorID != nil {
+		t.Fatalf("admin: expected unscoped list, got %+v (ok %v)", scope, ok)
+	}
+	if scope, ok := service.StudentListScopeFor(&model.Actor{Kind: model.ActorStudent, StudentID: &studentPK}); !ok || scope.StudentID == nil || *scope.StudentID != studentPK {
+		t.Fatalf("student: expected own record only, got %+v (ok %v)", scope, ok)
+	}
+	if scope, ok := service.StudentListScopeFor(&model.Actor{Kind: model.ActorLecturer, LecturerID: &lecturerPK}); !ok || scope.AdvisorID == nil || *scope.AdvisorID != lecturerPK {
+		t.Fatalf("lecturer: expected advisees only, got %+v (ok %v)", scope, ok)
+	}
+	for name, actor := range map[string]*model.Actor{
+		"custom role":         {Kind: model.ActorCustom},
+		"student w/o profile": {Kind: model.ActorStudent},
+		"no actor":            nil,
+	} {
+		if _, ok := service.StudentListScopeFor(actor); ok {
+			t.Fatalf("%s: expected no access", name)
+		}
+	}
+}
+
+func TestCanViewAdvisees(t *testing.T) {
+	lecturerPK := uuid.New()
+	otherPK := uuid.New()
+	lecturer := &model.Lecturer{ID: lecturerPK}
+
+	cases := []struct {
+		name  string
+		actor *model.Actor
+		want  bool
+	}{
+		{"admin", &model.Actor{Kind: model.ActorAdmin}, true},
+		{"self", &model.Actor{Kind: model.ActorLecturer, LecturerID: &lecturerPK}, true},
+		{"other lecturer", &model.Actor{Kind: model.ActorLecturer, LecturerID: &otherPK}, false},
+		{"student", &model.Actor{Kind: model.ActorStudent}, false},
+		{"no actor", nil, false},
+	}
+	for _, tc := range cases {
+		if got := service.CanViewAdvisees(tc.actor, lecturer); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+/* ==================== TEST ACHIEVEMENT WORKFLOW ============== */
+
+func TestNextAchievementStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		action  service.AchievementAction
+		from    model.AchievementStatus
+		kind    model.ActorKind
+		want    model.AchievementStatus
+		wantErr error
+	}{
+		{"student edits draft", service.ActionEdit, model.StatusDraft, model.ActorStudent, model.StatusDraft, nil},
+		{"student edits rejected", service.ActionEdit, model.StatusRejected, model.ActorStudent, model.StatusRevised, nil},
+		{"student edits changes_requested", service.ActionEdit, model.StatusChangesRequested, model.ActorStudent, model.StatusRevised, nil},
+		{"student resubmits revised", service.ActionSubmit, model.StatusRevised, model.ActorStudent, model.StatusSubmitted, nil},
+		{"lecturer requests changes", service.ActionRequestChanges, model.StatusSubmitted, model.ActorLecturer, model.StatusChangesRequested, nil},
+		{"lecturer cannot edit", service.ActionEdit, model.StatusDraft, model.ActorLecturer, "", service.ErrActionNotAllowed},
+		{"student cannot verify", service.ActionVerify, model.StatusSubmitted, model.ActorStudent, "", service.ErrActionNotAllowed},
+		{"cannot submit verified", service.ActionSubmit, model.StatusVerified, model.ActorStudent, "", service.ErrIllegalTransition},
+		{"cannot edit submitted", service.ActionEdit, model.StatusSubmitted, model.ActorStudent, "", service.ErrIllegalTransition},
+		{"cannot delete rejected", service.ActionDelete, model.StatusRejected, model.ActorStudent, "", service.ErrIllegalTransition},
+	}
+
+	for _, tc := range cases {
+		got, err := service.NextAchievementStatus(tc.action, tc.from, tc.kind)
+		if !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%s: expected error %v, got %v", tc.name, tc.wantErr, err)
+		}
+		if err == nil && got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+/* ================= TEST RECONCILE POSTGRES vs MONGO =========== */
+
+func TestFindAchievementStoreMismatches(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-service.ReconcileOrphanGrace)
+
+	refs := []model.AchievementReference{
+		{ID: uuid.New(), MongoAchievementID: "a", Status: model.StatusDraft},
+		{ID: uuid.New(), MongoAchievementID: "missing", Status: model.StatusSubmitted},
+		{ID: uuid.New(), MongoAchievementID: "gone", Status: model.StatusDeleted},
+		{ID: uuid.New(), MongoAchievementID: "kept", Status: model.StatusDeleted},
+	}
+	docs := map[string]time.Time{
+		"a":      now.Add(-time.Hour),
+		"kept":   now.Add(-time.Hour),
+		"orphan": now.Add(-time.Hour),
+		"fresh":  now, // Create mungkin masih berjalan
+	}
+
+	report := service.FindAchievementStoreMismatches(refs, docs, cutoff)
+
+	if len(report.Orphans) != 1 || report.Orphans[0] != "orphan" {
+		t.Fatalf("expected orphan [orphan], got %v", report.Orphans)
+	}
+	if len(report.Dangling) != 1 || report.Dangling[0].MongoAchievementID != "missing" {
+		t.Fatalf("expected dangling [missing], got %+v", report.Dangling)
+	}
+}
+
+/* ======================= TEST TRASH RETENTION ================= */
+
+func TestTrashRetention(t *testing.T) {
+	t.Setenv("ACHIEVEMENT_TRASH_RETENTION_DAYS", "")
+	if got := service.TrashRetention(); got != 30*24*time.Hour {
+		t.Fatalf("default retention should be 30 days, got %v", got)
+	}
+
+	t.Setenv("ACHIEVEMENT_TRASH_RETENTION_DAYS", "7")
+	if got := service.TrashRetention(); got != 7*24*time.Hour {
+		t.Fatalf("expected 7 days, got %v", got)
+	}
+
+	t.Setenv("ACHIEVEMENT_TRASH_RETENTION_DAYS", "-1")
+	if got := service.TrashRetention(); got != 30*24*time.Hour {
+		t.Fatalf("invalid value should fall back to default, got %v", got)
+	}
+}
+
+func TestMatchScoringRule(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(i int) *int { return &i }
+	rules := []model.ScoringRule{
+		{ID: uuid.New(), Level: str("national"), Points: 12},
+		{ID: uuid.New(), Level: str("national"), RankMin: num(1), RankMax: num(1), Points: 30},
+		{ID: uuid.New(), Level: str("national"), RankMin: num(1), RankMax: num(3), Participation: str(model.ParticipationTeam), Points: 18},
+		{ID: uuid.New(), Level: str("international"), Points: 20},
+		{ID: uuid.New(), Level: str("international"), Points: 99, Priority: 1},
+	}
+
+	tests := []struct {
+		name    string
+		details map[string]interface{}
+		want    int // -1 = tidak ada aturan cocok
+	}{
+		{"juara 1 individu", map[string]interface{}{"level": "national", "rank": float64(1)}, 30},
+		{"juara 1 tim lebih spesifik", map[string]interface{}{"level": "national", "rank": float64(1), "participation": "team"}, 18},
+		{"peringkat lain", map[string]interface{}{"level": "national", "rank": float64(7)}, 12},
+		{"tanpa rank", map[string]interface{}{"level": "national"}, 12},
+		{"priority menang", map[string]interface{}{"level": "international", "rank": float64(1)}, 99},
+		{"tidak cocok", map[string]interface{}{"level": "local"}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.MatchScoringRule(rules, tt.details)
+			if tt.want == -1 {
+				if got != nil {
+					t.Fatalf("expected no rule, got %d points", got.Points)
+				}
+				return
+			}
+			if got == nil || got.Points != tt.want {
+				t.Fatalf("expected %d points, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+/* ======================= TEST ATTACHMENT POLICY ================= */
+
+func TestAttachmentPolicy(t *testing.T) {
+	t.Setenv("ATTACHMENT_ALLOWED_TYPES", "")
+	t.Setenv("ATTACHMENT_MAX_COUNT", "2")
+	t.Setenv("ATTACHMENT_MAX_TOTAL_SIZE", "3MB")
+	policy := service.LoadAttachmentPolicy()
+
+	pdf := service.DetectAttachmentType([]byte("%PDF-1.7\n..."))
+	png := service.DetectAttachmentType([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	html := service.DetectAttachmentType([]byte("<html><script>alert(1)</script>"))
+	if pdf != "application/pdf" || png != "image/png" || html != "text/html" {
+		t.Fatalf("deteksi tipe salah: %s %s %s", pdf, png, html)
+	}
+
+	if err := policy.CheckFile(pdf, 2<<20); err != nil {
+		t.Errorf("pdf 2MB seharusnya diterima: %v", err)
+	}
+	if err := policy.CheckFile(html, 10); err == nil {
+		t.Error("html seharusnya ditolak walaupun client mengirim Content-Type application/pdf")
+	}
+	if err := policy.CheckFile(png, 6<<20); err == nil {
+		t.Error("png 6MB seharusnya melebihi batas default 5MB")
+	}
+
+	existing := []model.Attachment{{ID: "a", SHA256: "aaa", Size: 2 << 20}}
+	if err := policy.CheckQuota(existing, "bbb", 1<<20); err != nil {
+		t.Errorf("masih dalam kuota: %v", err)
+	}
+	if err := policy.CheckQuota(existing, "aaa", 1); err == nil {
+		t.Error("file dengan sha256 sama seharusnya ditolak")
+	}
+	if err := policy.CheckQuota(existing, "bbb", 2<<20); err == nil {
+		t.Error("total ukuran melebihi 3MB seharusnya ditolak")
+	}
+	if err := policy.CheckQuota(append(existing, model.Attachment{ID: "b", Size: 1}), "ccc", 1); err == nil {
+		t.Error("jumlah lampiran melebihi 2 seharusnya ditolak")
+	}
+}
+
+/* ======================= TEST ATTACHMENT SCAN ================= */
+
+func TestCheckAttachmentsScanned(t *testing.T) {
+	if err := service.CheckAttachmentsScanned(nil); err != nil {
+		t.Errorf("tanpa lampiran seharusnya boleh submit: %v", err)
+	}
+	ok := []model.Attachment{
+		{ID: "a", ScanStatus: model.ScanStatusClean},
+		{ID: "b", ScanStatus: model.ScanStatusSkipped},
+	}
+	if err := service.CheckAttachmentsScanned(ok); err != nil {
+		t.Errorf("lampiran bersih seharusnya boleh submit: %v", err)
+	}
+
+	for _, status := range []model.AttachmentScanStatus{model.ScanStatusInfected, model.ScanStatusPending, model.ScanStatusFailed, ""} {
+		err := service.CheckAttachmentsScanned(append(ok, model.Attachment{ID: "c", ScanStatus: status}))
+		var appErr *response.Error
+		if !errors.As(err, &appErr) || appErr.Status != fiber.StatusConflict {
+			t.Errorf("status %q seharusnya memblokir submit (409), dapat %v", status, err)
+		}
+	}
+}
+
+/* ======================= TEST REQUEST VALIDATION ================= */
+
+func TestCreateRequestsRequireKeys(t *testing.T) {
+	for name, req := range map[string]interface{}{
+		"student":    &model.CreateStudentRequest{},
+		"lecturer":   &model.CreateLecturerRequest{},
+		"permission": &model.AssignPermissionRequest{},
+	} {
+		errs, err := helper.ValidateStruct(req)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(errs) < 2 {
+			t.Errorf("%s: body kosong seharusnya ditolak, dapat %v", name, errs)
+		}
+	}
+}
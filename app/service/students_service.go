@@ -4,8 +4,8 @@ import (
 	"log"
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,21 +13,36 @@ import (
 
 // GetAllStudents godoc
 // @Summary      Lihat Semua Mahasiswa
-// @Description  Mendapatkan daftar semua mahasiswa yang terdaftar
+// @Description  Mendapatkan daftar mahasiswa sesuai scope aktor (Admin: semua, Dosen: bimbingan, Mahasiswa: dirinya sendiri)
 // @Tags         Students
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.Student}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "student_id, program_study, academic_year, created_at (prefix - untuk desc)"
+// @Param        program_study  query  string  false  "Filter program studi"
+// @Param        academic_year  query  string  false  "Filter angkatan"
+// @Param        department     query  string  false  "Filter departemen dosen wali"
+// @Success      200  {object}  fiber.Map{data=[]model.Student,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /students [get]
 func GetAllStudents(c *fiber.Ctx) error {
-	students, err := repository.GetAllStudents(database.DB)
+	scope, ok := StudentListScopeFor(currentActor(c))
+	if !ok {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data mahasiswa")
+	}
+	params, err := parseListParams(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false, "message": "Gagal mengambil data students",
-		})
+		return response.BadRequest(err.Error())
 	}
-	return c.JSON(fiber.Map{"success": true, "data": students})
+	students, err := repository.GetAllStudents(database.DB, params, scope)
+	if err != nil {
+		return listError(err, "Gagal mengambil data students")
+	}
+	return listResponse(c, params, students)
 }
 
 // GetStudentByUserID godoc
@@ -39,22 +54,26 @@ func GetAllStudents(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Router       /students/{id} [get]
 func GetStudentByUserID(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	user, err := repository.GetUserByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "User tidak ditemukan"})
+		return response.NotFound("User tidak ditemukan")
 	}
 
 	student, err := repository.GetStudentByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Data student tidak ditemukan"})
+		return response.NotFound("Data student tidak ditemukan")
+	}
+	if !CanViewStudentData(currentActor(c), student) {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data mahasiswa ini")
 	}
 
 	response := fiber.Map{
@@ -81,29 +100,42 @@ func GetStudentByUserID(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Produce      json
 // @Param        id   path      string  true  "User ID (UUID)"
-// @Success      200  {object}  fiber.Map{data=[]model.AchievementReference}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor"
+// @Param        status query  string  false  "Filter status (pisahkan koma)"
+// @Param        achievement_type query string false "Filter tipe prestasi"
+// @Param        tag    query  string  false  "Filter tag"
+// @Success      200  {object}  fiber.Map{data=[]model.AchievementListItem,meta=model.ListMeta,links=model.ListLinks}
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
 // @Router       /students/{id}/achievements [get]
 func GetStudentAchievements(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	// 1. Ambil data student dulu untuk dapat StudentPK (ID tabel student)
 	student, err := repository.GetStudentByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Mahasiswa tidak ditemukan"})
+		return response.NotFound("Mahasiswa tidak ditemukan")
+	}
+	if !CanViewStudentData(currentActor(c), student) {
+		return response.Forbidden("Forbidden: Anda tidak memiliki akses ke data mahasiswa ini")
 	}
 
-	// 2. Ambil achievement berdasarkan StudentPK
-	achievements, err := repository.GetAchievementsByStudentID(database.DB, student.ID)
+	// 2. Ambil achievement berdasarkan StudentPK (dosen wali tidak melihat draft)
+	params, err := parseListParams(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengambil data achievements"})
+		return response.BadRequest(err.Error())
 	}
-
-	return c.JSON(fiber.Map{"success": true, "data": achievements})
+	scope := repository.AchievementListScope{StudentID: &student.ID}
+	if actor := currentActor(c); actor != nil && actor.Kind == model.ActorLecturer {
+		scope.AdvisorID = actor.LecturerID
+	}
+	return listAchievementItems(c, scope, params)
 }
 
 // CreateStudent godoc
@@ -117,15 +149,12 @@ func GetStudentAchievements(c *fiber.Ctx) error {
 // @Success      201  {object}  fiber.Map{data=model.Student}
 // @Failure      400  {object}  fiber.Map
 // @Failure      409  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /students [post]
 func CreateStudent(c *fiber.Ctx) error {
 	var req model.CreateStudentRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
-	}
-
-	if req.UserID == uuid.Nil || req.StudentID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Field wajib: 'user_id' dan 'student_id'"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	student := &model.Student{
@@ -138,10 +167,10 @@ func CreateStudent(c *fiber.Ctx) error {
 
 	if err := repository.CreateStudent(database.DB, student); err != nil {
 		log.Println("Error membuat student:", err)
-		if strings.Contains(err.Error(), "duplicate key") {
-			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"success": false, "message": "Duplikat data", "error": err.Error()})
+		if response.IsUniqueViolation(err) {
+			return response.Conflict("Duplikat data")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal membuat student", "error": err.Error()})
+		return response.FromDB(err, "Gagal membuat student")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Data mahasiswa berhasil ditambahkan", "data": student})
@@ -149,7 +178,7 @@ func CreateStudent(c *fiber.Ctx) error {
 
 // UpdateStudent godoc
 // @Summary      Update Profil Mahasiswa
-// @Description  Mengubah data NIM, Prodi, atau Dosen Wali
+// @Description  Mengubah data NIM, Prodi, atau Dosen Wali (Admin, permission student:update)
 // @Tags         Students
 // @Security     BearerAuth
 // @Accept       json
@@ -158,22 +187,24 @@ func CreateStudent(c *fiber.Ctx) error {
 // @Param        request body model.UpdateStudentRequest true "Data Update"
 // @Success      200  {object}  fiber.Map{data=model.Student}
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /students/{id} [put]
 func UpdateStudent(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	var req model.UpdateStudentRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	student, err := repository.GetStudentByID(database.DB, userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Data student tidak ditemukan"})
+		return response.NotFound("Data student tidak ditemukan")
 	}
 
 	if req.StudentID != "" { student.StudentID = req.StudentID }
@@ -183,7 +214,7 @@ func UpdateStudent(c *fiber.Ctx) error {
 	student.AdvisorID = req.AdvisorID // Bisa null
 
 	if err := repository.UpdateStudent(database.DB, student); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal update", "error": err.Error()})
+		return response.FromDB(err, "Gagal update")
 	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Data student berhasil diupdate", "data": student})
@@ -192,7 +223,7 @@ func UpdateStudent(c *fiber.Ctx) error {
 // Update Advisor Only
 // UpdateStudentAdvisor godoc
 // @Summary      Update Dosen Wali
-// @Description  Mengubah dosen wali mahasiswa tertentu
+// @Description  Mengubah dosen wali mahasiswa tertentu (Admin, permission student:update)
 // @Tags         Students
 // @Security     BearerAuth
 // @Accept       json
@@ -201,23 +232,25 @@ func UpdateStudent(c *fiber.Ctx) error {
 // @Param        request body model.UpdateAdvisorRequest true "Dosen Wali ID (Lecturer UUID)"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      403  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /students/{id}/advisor [put]
 func UpdateStudentAdvisor(c *fiber.Ctx) error {
 	userID, err := uuid.Parse(c.Params("id")) // Ini adalah User ID dari URL
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "ID tidak valid"})
+		return response.BadRequest("ID tidak valid")
 	}
 
 	var req model.UpdateAdvisorRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Request body tidak valid"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	// Update menggunakan UserID
 	err = repository.UpdateAdvisor(database.DB, userID, req.AdvisorID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Gagal mengubah advisor"})
+		return response.FromDB(err, "Gagal mengubah advisor")
 	}
 
 	return c.JSON(fiber.Map{"success": true, "message": "Advisor berhasil diperbarui"})
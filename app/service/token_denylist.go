@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"log"
+	"project-uas/app/repository"
+	"project-uas/database"
+	"project-uas/helper"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Denylist access token: cache in-memory di depan tabel revoked_tokens & user_token_revocations.
+// Hasil "tidak dicabut" hanya di-cache sebentar agar pencabutan dari instance lain tetap cepat terlihat.
+const denylistNegativeTTL = time.Minute
+
+var (
+	revokedTokenCache  = helper.NewTTLCache[string, bool]()
+	revokedBeforeCache = helper.NewTTLCache[uuid.UUID, time.Time]()
+)
+
+// IsAccessTokenRevoked dipanggil oleh middleware.AuthProtected untuk setiap request
+func IsAccessTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	revoked, ok := revokedTokenCache.Get(jti)
+	if !ok {
+		var err error
+		revoked, err = repository.IsAccessTokenRevoked(database.DB, jti)
+		if err != nil {
+			return false, err
+		}
+		revokedTokenCache.Set(jti, revoked, denylistNegativeTTL)
+	}
+	if revoked {
+		return true, nil
+	}
+
+	revokedBefore, ok := revokedBeforeCache.Get(userID)
+	if !ok {
+		t, err := repository.GetUserTokensRevokedBefore(database.DB, userID)
+		if err != nil {
+			return false, err
+		}
+		if t != nil {
+			revokedBefore = *t
+		}
+		revokedBeforeCache.Set(userID, revokedBefore, denylistNegativeTTL)
+	}
+	return IsRevokedByLogoutAll(issuedAt, revokedBefore), nil
+}
+
+// IsRevokedByLogoutAll: Token terbit sebelum logout-all (revokedBefore nol = user belum pernah logout-all).
+// Keduanya presisi mikrodetik (claim iat_us & TIMESTAMPTZ), sehingga login ulang tepat setelah logout-all tetap valid.
+func IsRevokedByLogoutAll(issuedAt, revokedBefore time.Time) bool {
+	return !revokedBefore.IsZero() && issuedAt.Before(revokedBefore)
+}
+
+// revokeAccessToken mencabut satu access token (logout sesi saat ini)
+func revokeAccessToken(jti string, userID uuid.UUID, expiresAt time.Time) error {
+	if err := repository.RevokeAccessToken(database.DB, jti, userID, expiresAt); err != nil {
+		return err
+	}
+	revokedTokenCache.Set(jti, true, time.Until(expiresAt))
+	return nil
+}
+
+// revokeAllUserSessions mencabut semua access token & refresh token milik user
+func revokeAllUserSessions(userID uuid.UUID) error {
+	now := time.Now().Truncate(time.Microsecond) // Presisi TIMESTAMPTZ
+	if err := repository.SetUserTokensRevokedBefore(database.DB, userID, now); err != nil {
+		return err
+	}
+	if err := repository.RevokeUserRefreshTokens(database.DB, userID); err != nil {
+		return err
+	}
+	revokedBeforeCache.Set(userID, now, denylistNegativeTTL)
+	return nil
+}
+
+// StartRevokedTokenCleanupWorker membersihkan denylist dari token yang sudah kadaluwarsa sampai ctx dibatalkan
+func StartRevokedTokenCleanupWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := CleanupRevokedTokens(); err != nil {
+				log.Println("bersihkan denylist token:", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CleanupRevokedTokens menghapus jti yang sudah kadaluwarsa dan logout-all yang lebih lama dari masa
+// berlaku access token (semua token yang terbit sebelumnya sudah kadaluwarsa)
+func CleanupRevokedTokens() error {
+	if err := repository.DeleteExpiredRevokedTokens(database.DB); err != nil {
+		return err
+	}
+	return repository.DeleteUserTokenRevocationsBefore(database.DB, time.Now().Add(-helper.AccessTokenTTL))
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"project-uas/app/model"
 	"project-uas/app/repository"
+	"project-uas/app/response"
 	"project-uas/database"
 	"project-uas/helper"
 
@@ -17,15 +18,27 @@ import (
 // @Tags         Users
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object}  fiber.Map{data=[]model.User}
+// @Param        page   query  int     false  "Halaman (default 1)"
+// @Param        limit  query  int     false  "Jumlah per halaman (default 20, maks 100)"
+// @Param        after  query  string  false  "Cursor dari meta.next_cursor (menggantikan page)"
+// @Param        sort   query  string  false  "full_name, username, email, created_at, updated_at (prefix - untuk desc)"
+// @Param        role_id    query  string  false  "Filter role"
+// @Param        is_active  query  bool    false  "Filter status aktif"
+// @Param        full_name  query  string  false  "Cari nama (mengandung)"
+// @Success      200  {object}  fiber.Map{data=[]model.User,meta=model.ListMeta,links=model.ListLinks}
+// @Failure      400  {object}  fiber.Map
 // @Failure      500  {object}  fiber.Map
 // @Router       /users [get]
 func GetAllUsers(c *fiber.Ctx) error {
-	users, err := repository.GetAllUsers(database.DB)
+	params, err := parseListParams(c)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+	users, err := repository.GetAllUsers(database.DB, params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return listError(err, "Gagal mengambil data users")
 	}
-	return c.JSON(fiber.Map{"success": true, "data": users})
+	return listResponse(c, params, users)
 }
 
 // GET /api/v1/users/:id
@@ -42,11 +55,11 @@ func GetAllUsers(c *fiber.Ctx) error {
 func GetUserByID(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid ID"})
+		return response.BadRequest("Invalid ID")
 	}
 	user, err := repository.GetUserByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "User not found"})
+		return response.NotFound("User not found")
 	}
 	return c.JSON(fiber.Map{"success": true, "data": user})
 }
@@ -62,16 +75,17 @@ func GetUserByID(c *fiber.Ctx) error {
 // @Param        request body model.CreateUserRequest true "Data User"
 // @Success      201  {object}  fiber.Map{data=model.User}
 // @Failure      400  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /users [post]
 func CreateUser(c *fiber.Ctx) error {
 	var req model.CreateUserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid Body"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	hash, err := helper.HashPassword(req.Password)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Hash failed"})
+		return response.Internal("Hash failed", err)
 	}
 
 	user := &model.User{
@@ -83,7 +97,7 @@ func CreateUser(c *fiber.Ctx) error {
 	}
 
 	if err := repository.CreateUser(database.DB, user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return response.FromDB(err, "Terjadi kesalahan pada server")
 	}
 	user.PasswordHash = ""
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "data": user})
@@ -100,31 +114,40 @@ func CreateUser(c *fiber.Ctx) error {
 // @Success      200  {object}  fiber.Map{data=model.User}
 // @Failure      400  {object}  fiber.Map
 // @Failure      404  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /users/{id} [put]
 func UpdateUser(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid ID"})
+		return response.BadRequest("Invalid ID")
 	}
 	var req model.UpdateUserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid Body"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	user, err := repository.GetUserByID(database.DB, id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "User not found"})
+		return response.NotFound("User not found")
 	}
 
 	if req.Username != "" { user.Username = req.Username }
 	if req.Email != "" { user.Email = req.Email }
 	if req.FullName != "" { user.FullName = req.FullName }
-	if req.RoleID != uuid.Nil { user.RoleID = req.RoleID }
+	if req.RoleID != uuid.Nil {
+		if msg, err := guardSelfRoleChange(c, id, req.RoleID); err != nil {
+			return response.Internal("Gagal memeriksa role admin", err)
+		} else if msg != "" {
+			return response.Conflict(msg)
+		}
+		user.RoleID = req.RoleID
+	}
 	if req.IsActive != nil { user.IsActive = *req.IsActive }
 
 	if err := repository.UpdateUser(database.DB, user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return response.FromDB(err, "Terjadi kesalahan pada server")
 	}
+	invalidateUserAuthState(user.ID)
 	return c.JSON(fiber.Map{"success": true, "data": user})
 }
 
@@ -142,11 +165,12 @@ func UpdateUser(c *fiber.Ctx) error {
 func DeleteUser(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid ID"})
+		return response.BadRequest("Invalid ID")
 	}
 	if err := repository.DeleteUser(database.DB, id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return response.FromDB(err, "Terjadi kesalahan pada server")
 	}
+	invalidateUserAuthState(id)
 	return c.JSON(fiber.Map{"success": true, "message": "User deleted"})
 }
 
@@ -162,19 +186,54 @@ func DeleteUser(c *fiber.Ctx) error {
 // @Param        request body model.UpdateUserRoleRequest true "Role ID Baru"
 // @Success      200  {object}  fiber.Map
 // @Failure      400  {object}  fiber.Map
+// @Failure      422  {object}  fiber.Map
 // @Router       /users/{id}/role [put]
 func UpdateUserRole(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid ID"})
+		return response.BadRequest("Invalid ID")
 	}
 	var req model.UpdateUserRoleRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid Body"})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if msg, err := guardSelfRoleChange(c, id, req.RoleID); err != nil {
+		return response.Internal("Gagal memeriksa role admin", err)
+	} else if msg != "" {
+		return response.Conflict(msg)
 	}
 
 	if err := repository.UpdateUserRole(database.DB, id, req.RoleID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return response.FromDB(err, "Terjadi kesalahan pada server")
 	}
+	invalidateUserAuthState(id)
 	return c.JSON(fiber.Map{"success": true, "message": "Role updated"})
-}
\ No newline at end of file
+}
+
+// POST /api/v1/users/:id/logout-all
+// RevokeUserSessions godoc
+// @Summary      Logout Paksa Semua Sesi User
+// @Description  Admin mencabut semua access token & refresh token milik user tertentu
+// @Tags         Users
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "User ID (UUID)"
+// @Success      200  {object}  fiber.Map
+// @Failure      400  {object}  fiber.Map
+// @Failure      404  {object}  fiber.Map
+// @Router       /users/{id}/logout-all [post]
+func RevokeUserSessions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest("Invalid ID")
+	}
+	if _, err := repository.GetUserByID(database.DB, id); err != nil {
+		return response.NotFound("User not found")
+	}
+
+	if err := revokeAllUserSessions(id); err != nil {
+		return response.Internal("Gagal mencabut sesi user", err)
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "All sessions revoked"})
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"fmt"
+	"project-uas/app/response"
+	"project-uas/database"
+	"project-uas/helper"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Query cek keberadaan untuk rule `exists=<nama>` (nama dari tag struct, bukan input user)
+var existsQueries = map[string]string{
+	"users":             "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)",
+	"roles":             "SELECT EXISTS(SELECT 1 FROM roles WHERE id = $1)",
+	"permissions":       "SELECT EXISTS(SELECT 1 FROM permissions WHERE id = $1)",
+	"students":          "SELECT EXISTS(SELECT 1 FROM students WHERE id = $1)",
+	"lecturers":         "SELECT EXISTS(SELECT 1 FROM lecturers WHERE id = $1)",
+	"achievement_types": "SELECT EXISTS(SELECT 1 FROM achievement_types WHERE code = $1 AND is_active = TRUE)",
+}
+
+func init() {
+	helper.RegisterValidation("exists", func(value reflect.Value, param string) (string, error) {
+		query, ok := existsQueries[param]
+		if !ok {
+			return "", fmt.Errorf("exists: tabel %q tidak terdaftar", param)
+		}
+		var found bool
+		if err := database.DB.QueryRow(query, value.Interface()).Scan(&found); err != nil {
+			return "", err
+		}
+		if !found {
+			return "tidak ditemukan", nil
+		}
+		return "", nil
+	})
+}
+
+// bindAndValidate mem-parse body ke req lalu menjalankan validasi tag `validate`.
+// Mengembalikan 400 (body rusak), 422 (field tidak valid) atau 500 sebagai *response.Error.
+func bindAndValidate(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return response.BadRequest("Request body tidak valid")
+	}
+	fieldErrs, err := helper.ValidateStruct(req)
+	if err != nil {
+		return response.Internal("Gagal memvalidasi request", err)
+	}
+	if len(fieldErrs) > 0 {
+		return response.Validation(fieldErrs)
+	}
+	return nil
+}
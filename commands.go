@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"project-uas/app/service"
+	"project-uas/database"
+)
+
+// runCommand menjalankan subcommand CLI (go run . <command>). Mengembalikan false jika
+// tidak ada subcommand sehingga main menjalankan server HTTP seperti biasa.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "reconcile":
+		reconcileCommand(args[1:])
+	case "recalculate-points":
+		recalculatePointsCommand(args[1:])
+	case "migrate":
+		migrateCommand(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "perintah tidak dikenal: %s\n\nPerintah:\n"+
+			"  reconcile [-fix]                   cek konsistensi achievement_references vs Mongo\n"+
+			"  recalculate-points [-type <code>]  hitung ulang poin prestasi dari scoring_rules\n"+
+			"  migrate up|down [-steps n]|status  migrasi skema Postgres & index Mongo\n", args[0])
+		os.Exit(2)
+	}
+	return true
+}
+
+// reconcile [-fix]: laporkan (dan perbaiki) dokumen Mongo orphan & reference tanpa dokumen
+func reconcileCommand(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "hapus dokumen orphan dan soft delete reference tanpa dokumen")
+	fs.Parse(args)
+
+	database.ConnectDB()
+
+	report, err := service.ReconcileAchievements(*fix)
+	if report != nil {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		log.Fatal("Reconcile gagal:", err)
+	}
+	if !*fix && (len(report.Orphans) > 0 || len(report.Dangling) > 0) {
+		os.Exit(1)
+	}
+}
+
+// recalculate-points [-type code]: hitung ulang poin (mis. setelah migrasi data lama dengan poin isian mahasiswa)
+func recalculatePointsCommand(args []string) {
+	fs := flag.NewFlagSet("recalculate-points", flag.ExitOnError)
+	achievementType := fs.String("type", "", "hanya tipe prestasi ini (kosong = semua)")
+	fs.Parse(args)
+
+	database.ConnectDB()
+
+	updated, err := service.RecalculateAchievementPoints(*achievementType)
+	fmt.Printf("%d prestasi diperbarui\n", updated)
+	if err != nil {
+		log.Fatal("Hitung ulang poin gagal:", err)
+	}
+}
+
+// migrate up | down [-steps n] | status: migrasi skema yang di-embed di binary (database/migrations)
+func migrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "pemakaian: migrate up | down [-steps n] | status")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	steps := fs.Int("steps", 1, "jumlah migrasi yang di-rollback (hanya untuk down)")
+	fs.Parse(args[1:])
+
+	database.ConnectDB()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(database.DB, database.MongoDB)
+		for _, m := range applied {
+			fmt.Printf("up    %04d_%s (%s)\n", m.Version, m.Name, m.Kind)
+		}
+		if err != nil {
+			log.Fatal("Migrate up gagal: ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Skema sudah up to date")
+		}
+	case "down":
+		reverted, err := database.MigrateDown(database.DB, database.MongoDB, *steps)
+		for _, m := range reverted {
+			fmt.Printf("down  %04d_%s (%s)\n", m.Version, m.Name, m.Kind)
+		}
+		if err != nil {
+			log.Fatal("Migrate down gagal: ", err)
+		}
+	case "status":
+		states, err := database.GetMigrationStatus(database.DB)
+		if err != nil {
+			log.Fatal("Gagal membaca status migrasi: ", err)
+		}
+		failed := false
+		for _, s := range states {
+			status := "pending"
+			switch {
+			case s.ChecksumMismatch:
+				status, failed = "CHECKSUM BERUBAH", true
+			case s.Name == "":
+				status = "applied (tidak dikenal binary ini)"
+			case s.AppliedAt != nil:
+				status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %-5s  %s\n", s.Version, s.Name, s.Kind, status)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "subcommand migrate tidak dikenal: %s\n", args[0])
+		os.Exit(2)
+	}
+}
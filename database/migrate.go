@@ -0,0 +1,510 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// File migrasi ikut di-embed ke binary. Penamaan:
+//
+//	NNNN_nama.up.sql / NNNN_nama.down.sql  -> DDL Postgres (satu transaksi per migrasi)
+//	NNNN_nama.mongo.json                   -> index Mongo (down = drop index yang sama)
+//
+// Versi Postgres & Mongo berbagi satu urutan dan dicatat di tabel schema_migrations.
+//
+// Perubahan skema wajib dikirim bersama migrasinya dalam commit yang sama (file migrasi baru,
+// jangan mengubah file yang sudah dirilis karena checksum-nya dicek). 0001–0007 adalah
+// pengecualian: skema yang sebelumnya dibuat manual dikumpulkan saat runner ini diperkenalkan,
+// jadi commit sebelum runner butuh `migrate up` dari versi ini untuk dijalankan:
+//
+//	0001_initial_schema              -> skema awal + achievement_references.deleted_at (soft delete)
+//	0002_auth_tokens                 -> refresh_tokens (rotasi), revoked_tokens & user_token_revocations (denylist)
+//	0003_achievement_status_events   -> log transisi status + backfill
+//	0004_achievement_outbox          -> outbox sinkronisasi Postgres -> Mongo
+//	0005_achievement_types           -> registry tipe prestasi
+//	0006_scoring_rules               -> aturan poin
+//	0007_achievement_indexes         -> index Mongo (list, text search, versi, revisi)
+//
+//go:embed migrations/*
+var migrationFiles embed.FS
+
+const (
+	MigrationSQL   = "sql"
+	MigrationMongo = "mongo"
+)
+
+// Key pg_advisory_lock agar dua proses migrate tidak berjalan bersamaan
+const migrationLockKey = 72010601
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up\.sql|down\.sql|mongo\.json)$`)
+
+// Migration: Satu versi skema
+type Migration struct {
+	Version  int
+	Name     string
+	Kind     string // sql / mongo
+	Checksum string // sha256 file up / mongo.json, dicek ulang terhadap schema_migrations
+	up       string
+	down     string
+}
+
+// MigrationState: Migrasi beserta status penerapannya (untuk `migrate status`)
+type MigrationState struct {
+	Migration
+	AppliedAt        *time.Time
+	ChecksumMismatch bool // File berubah setelah diterapkan
+}
+
+// ErrChecksumMismatch: Migrasi yang sudah diterapkan diubah isinya (harus dibuat versi baru)
+var ErrChecksumMismatch = errors.New("checksum migrasi tidak cocok dengan schema_migrations")
+
+// mongoIndexSpec: Satu index di file NNNN_nama.mongo.json
+type mongoIndexSpec struct {
+	Collection      string             `json:"collection"`
+	Name            string             `json:"name"`
+	Keys            [][2]interface{}   `json:"keys"` // [["field", 1]] urutan dipertahankan
+	Unique          bool               `json:"unique,omitempty"`
+	Weights         map[string]float64 `json:"weights,omitempty"`
+	DefaultLanguage string             `json:"default_language,omitempty"`
+}
+
+type mongoMigration struct {
+	Indexes []mongoIndexSpec `json:"indexes"`
+}
+
+// Index unik atas nomor urut (collection.index -> field nomor). Data lama bisa berisi nomor ganda dari
+// penomoran baca-lalu-tulis, jadi duplikatnya dinomori ulang sebelum index dibuat (renumberDuplicates).
+var mongoSequenceIndexes = map[string]string{
+	"achievement_versions.achievementId_1_version_1":   "version",
+	"achievement_revisions.achievementId_1_revision_1": "revision",
+}
+
+// LoadMigrations membaca migrasi yang di-embed, urut berdasarkan versi
+func LoadMigrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "migrations")
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		kind := MigrationSQL
+		if m[3] == "mongo.json" {
+			kind = MigrationMongo
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2], Kind: kind}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] || mig.Kind != kind {
+			return nil, fmt.Errorf("versi migrasi %d dipakai lebih dari satu migrasi", version)
+		}
+
+		switch m[3] {
+		case "up.sql", "mongo.json":
+			if kind == MigrationMongo {
+				var spec mongoMigration
+				if err := json.Unmarshal(content, &spec); err != nil {
+					return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+				}
+			}
+			mig.up = string(content)
+			sum := sha256.Sum256(content)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		case "down.sql":
+			mig.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" {
+			return nil, fmt.Errorf("migrasi %04d_%s tidak punya file up", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func ensureMigrationTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			kind       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	return err
+}
+
+type appliedMigration struct {
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func getAppliedMigrations(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}) (map[int]appliedMigration, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// GetMigrationStatus: Semua migrasi beserta waktu diterapkan dan hasil verifikasi checksum.
+// Versi yang tercatat di schema_migrations tapi tidak ada di binary ikut dilaporkan (Name kosong).
+func GetMigrationStatus(db *sql.DB) ([]MigrationState, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int]appliedMigration{}
+	if exists {
+		if applied, err = getAppliedMigrations(context.Background(), db); err != nil {
+			return nil, err
+		}
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if a, ok := applied[m.Version]; ok {
+			at := a.AppliedAt
+			state.AppliedAt = &at
+			state.ChecksumMismatch = a.Checksum != m.Checksum
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for version, a := range applied {
+		at := a.AppliedAt
+		states = append(states, MigrationState{Migration: Migration{Version: version, Checksum: a.Checksum}, AppliedAt: &at})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// CheckMigrations dipanggil saat server start: gagal jika ada migrasi pending atau checksum berubah
+func CheckMigrations(db *sql.DB) error {
+	states, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, s := range states {
+		if s.ChecksumMismatch {
+			return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, s.Version, s.Name)
+		}
+		if s.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d migrasi belum diterapkan, jalankan `migrate up`", pending)
+	}
+	return nil
+}
+
+// withMigrationLock menjalankan fn dengan satu koneksi yang memegang advisory lock migrasi
+func withMigrationLock(db *sql.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if err := ensureMigrationTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(ctx, conn)
+}
+
+// MigrateUp menerapkan semua migrasi pending secara berurutan. Berhenti (tanpa menerapkan apa pun)
+// jika migrasi yang sudah diterapkan berubah checksum-nya. Mengembalikan migrasi yang diterapkan.
+func MigrateUp(db *sql.DB, mdb *mongo.Database) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if a, ok := applied[m.Version]; ok && a.Checksum != m.Checksum {
+				return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, m.Version, m.Name)
+			}
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := applyMigration(ctx, conn, mdb, m, true); err != nil {
+				return fmt.Errorf("migrasi %04d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown membatalkan `steps` migrasi terakhir yang sudah diterapkan (terbaru lebih dulu)
+func MigrateDown(db *sql.DB, mdb *mongo.Database, steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]Migration{}
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	var done []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := getAppliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			m, ok := byVersion[versions[i]]
+			if !ok {
+				return fmt.Errorf("migrasi versi %d tidak ada di binary ini", versions[i])
+			}
+			if applied[m.Version].Checksum != m.Checksum {
+				return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, m.Version, m.Name)
+			}
+			if m.Kind == MigrationSQL && m.down == "" {
+				return fmt.Errorf("migrasi %04d_%s tidak punya file down", m.Version, m.Name)
+			}
+			if err := applyMigration(ctx, conn, mdb, m, false); err != nil {
+				return fmt.Errorf("rollback %04d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// applyMigration menjalankan up/down lalu mencatat / menghapus baris schema_migrations.
+// SQL dan pencatatannya berada di satu transaksi; index Mongo tidak transaksional tapi idempoten.
+func applyMigration(ctx context.Context, conn *sql.Conn, mdb *mongo.Database, m Migration, up bool) error {
+	if m.Kind == MigrationMongo {
+		if err := runMongoMigration(ctx, mdb, m, up); err != nil {
+			return err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.Kind == MigrationSQL {
+		script := m.down
+		if up {
+			script = m.up
+		}
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO schema_migrations (version, name, kind, checksum, applied_at) VALUES ($1, $2, $3, $4, NOW())
+		`, m.Version, m.Name, m.Kind, m.Checksum)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func runMongoMigration(ctx context.Context, mdb *mongo.Database, m Migration, up bool) error {
+	if mdb == nil {
+		return errors.New("koneksi MongoDB belum tersedia")
+	}
+	var spec mongoMigration
+	if err := json.Unmarshal([]byte(m.up), &spec); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	for _, idx := range spec.Indexes {
+		indexes := mdb.Collection(idx.Collection).Indexes()
+		if !up {
+			_, err := indexes.DropOne(ctx, idx.Name)
+			var cmdErr mongo.CommandError
+			if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound") {
+				return err
+			}
+			continue
+		}
+
+		if field, ok := mongoSequenceIndexes[idx.Collection+"."+idx.Name]; ok && idx.Unique {
+			if err := renumberDuplicates(ctx, mdb.Collection(idx.Collection), idx.Keys, field); err != nil {
+				return fmt.Errorf("index %s.%s: %w", idx.Collection, idx.Name, err)
+			}
+		}
+
+		opts := options.Index().SetName(idx.Name)
+		if idx.Unique {
+			opts.SetUnique(true)
+		}
+		if len(idx.Weights) > 0 {
+			opts.SetWeights(idx.Weights)
+		}
+		if idx.DefaultLanguage != "" {
+			opts.SetDefaultLanguage(idx.DefaultLanguage)
+		}
+		if _, err := indexes.CreateOne(ctx, mongo.IndexModel{Keys: indexKeys(idx.Keys), Options: opts}); err != nil {
+			return fmt.Errorf("index %s.%s: %w", idx.Collection, idx.Name, err)
+		}
+	}
+	return nil
+}
+
+// renumberDuplicates memberi nomor baru (setelah nomor terbesar di grupnya) ke dokumen yang nomornya
+// ganda. Dokumen tertua (_id terkecil) mempertahankan nomornya; tidak ada dokumen yang dihapus.
+func renumberDuplicates(ctx context.Context, coll *mongo.Collection, keys [][2]interface{}, field string) error {
+	group := bson.M{}
+	var groupFields []string
+	for _, k := range keys {
+		name, _ := k[0].(string)
+		group[name] = "$" + name
+		if name != field {
+			groupFields = append(groupFields, name)
+		}
+	}
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{"_id": group, "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	var dups []struct {
+		Key bson.M        `bson:"_id"`
+		IDs []interface{} `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &dups); err != nil {
+		return err
+	}
+
+	for _, d := range dups {
+		filter := bson.M{}
+		for _, name := range groupFields {
+			filter[name] = d.Key[name]
+		}
+		var latest bson.M
+		opts := options.FindOne().SetSort(bson.M{field: -1}).SetProjection(bson.M{field: 1})
+		if err := coll.FindOne(ctx, filter, opts).Decode(&latest); err != nil {
+			return err
+		}
+		var next int64
+		switch n := latest[field].(type) {
+		case int32:
+			next = int64(n)
+		case int64:
+			next = n
+		case float64:
+			next = int64(n)
+		}
+		for _, id := range d.IDs[1:] {
+			next++
+			if _, err := coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{field: next}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indexKeys: [["field", 1]] -> bson.D, angka JSON (float64) dijadikan int32 seperti spec index biasa
+func indexKeys(keys [][2]interface{}) bson.D {
+	d := make(bson.D, 0, len(keys))
+	for _, k := range keys {
+		field, _ := k[0].(string)
+		value := k[1]
+		if f, ok := value.(float64); ok {
+			value = int32(f)
+		}
+		d = append(d, bson.E{Key: field, Value: value})
+	}
+	return d
+}
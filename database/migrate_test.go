@@ -0,0 +1,125 @@
+package database
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("tidak ada migrasi yang di-embed")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("versi %d di posisi %d, versi harus berurutan tanpa lompatan", m.Version, i)
+		}
+		if len(m.Checksum) != 64 {
+			t.Errorf("%04d_%s: checksum kosong", m.Version, m.Name)
+		}
+		if m.Kind == MigrationSQL && strings.TrimSpace(m.down) == "" {
+			t.Errorf("%04d_%s: migrasi SQL harus punya file down", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_indexes.mongo.json": {Data: []byte(`{"indexes": [{"collection": "a", "name": "x_1", "keys": [["x", 1]]}]}`)},
+		"m/0001_init.up.sql":        {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_init.down.sql":      {Data: []byte("DROP TABLE a;")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "init" || migrations[1].Kind != MigrationMongo {
+		t.Fatalf("hasil load tidak sesuai: %+v", migrations)
+	}
+	if migrations[0].down != "DROP TABLE a;" {
+		t.Errorf("file down tidak terbaca: %q", migrations[0].down)
+	}
+
+	// Checksum hanya bergantung pada isi file up
+	fsys["m/0001_init.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE IF EXISTS a;")}
+	again, _ := loadMigrations(fsys, "m")
+	if again[0].Checksum != migrations[0].Checksum {
+		t.Error("checksum berubah padahal file up sama")
+	}
+	fsys["m/0001_init.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id BIGINT);")}
+	again, _ = loadMigrations(fsys, "m")
+	if again[0].Checksum == migrations[0].Checksum {
+		t.Error("checksum tidak berubah padahal file up diubah")
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"nama tidak valid": {"m/init.sql": {Data: []byte("")}},
+		"versi ganda": {
+			"m/0001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"m/0001_b.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"tanpa up":   {"m/0001_a.down.sql": {Data: []byte("SELECT 1;")}},
+		"json rusak": {"m/0001_a.mongo.json": {Data: []byte("{")}},
+	}
+	for name, fsys := range invalid {
+		if _, err := loadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: seharusnya error", name)
+		}
+	}
+}
+
+func TestIndexKeys(t *testing.T) {
+	got := indexKeys([][2]interface{}{{"studentId", 1.0}, {"createdAt", -1.0}, {"$**", "text"}})
+	want := bson.D{{Key: "studentId", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}, {Key: "$**", Value: "text"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMongoSequenceIndexesExist(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, m := range migrations {
+		if m.Kind != MigrationMongo {
+			continue
+		}
+		var spec mongoMigration
+		if err := json.Unmarshal([]byte(m.up), &spec); err != nil {
+			t.Fatal(err)
+		}
+		for _, idx := range spec.Indexes {
+			field, ok := mongoSequenceIndexes[idx.Collection+"."+idx.Name]
+			if !ok {
+				continue
+			}
+			if !idx.Unique {
+				t.Errorf("%s.%s: index nomor urut harus unik", idx.Collection, idx.Name)
+			}
+			for _, k := range idx.Keys {
+				if k[0] == field {
+					found[idx.Collection+"."+idx.Name] = true
+				}
+			}
+		}
+	}
+	for name, field := range mongoSequenceIndexes {
+		if !found[name] {
+			t.Errorf("%s: tidak ada index unik dengan field %s di migrasi", name, field)
+		}
+	}
+}
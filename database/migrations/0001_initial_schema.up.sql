@@ -0,0 +1,72 @@
+-- Skema inti RBAC, profil mahasiswa/dosen, dan reference prestasi.
+-- IF NOT EXISTS agar database lama (tabel dibuat manual) bisa langsung di-baseline dengan `migrate up`.
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          UUID PRIMARY KEY,
+    name        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id          UUID PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE, -- format resource:action
+    resource    VARCHAR(50) NOT NULL,
+    action      VARCHAR(50) NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id       UUID NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            UUID PRIMARY KEY,
+    username      VARCHAR(50) NOT NULL UNIQUE,
+    email         VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    full_name     VARCHAR(100) NOT NULL,
+    role_id       UUID NOT NULL REFERENCES roles (id), -- Role yang masih dipakai tidak bisa dihapus (409)
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_users_role_id ON users (role_id);
+
+CREATE TABLE IF NOT EXISTS lecturers (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    lecturer_id VARCHAR(20) NOT NULL UNIQUE,
+    department  VARCHAR(100),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS students (
+    id            UUID PRIMARY KEY,
+    user_id       UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    student_id    VARCHAR(20) NOT NULL UNIQUE,
+    program_study VARCHAR(100),
+    academic_year VARCHAR(10),
+    advisor_id    UUID REFERENCES lecturers (id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_students_advisor_id ON students (advisor_id);
+
+CREATE TABLE IF NOT EXISTS achievement_references (
+    id                   UUID PRIMARY KEY,
+    student_id           UUID NOT NULL REFERENCES students (id), -- Mahasiswa dengan prestasi tidak bisa dihapus (409)
+    mongo_achievement_id VARCHAR(24) NOT NULL UNIQUE,
+    status               VARCHAR(20) NOT NULL DEFAULT 'draft',
+    submitted_at         TIMESTAMPTZ,
+    verified_at          TIMESTAMPTZ,
+    verified_by          UUID REFERENCES users (id) ON DELETE SET NULL,
+    rejection_note       TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at           TIMESTAMPTZ
+);
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_achievement_references_student_id ON achievement_references (student_id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_status ON achievement_references (status);
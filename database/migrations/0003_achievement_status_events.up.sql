@@ -0,0 +1,35 @@
+-- Event log perubahan status prestasi (sumber /achievements/:id/history)
+CREATE TABLE IF NOT EXISTS achievement_status_events (
+    id             UUID PRIMARY KEY,
+    achievement_id UUID NOT NULL REFERENCES achievement_references (id) ON DELETE CASCADE,
+    old_status     VARCHAR(20), -- NULL untuk event pembuatan draft
+    new_status     VARCHAR(20) NOT NULL,
+    actor_user_id  UUID REFERENCES users (id) ON DELETE SET NULL,
+    actor_role     VARCHAR(50) NOT NULL DEFAULT '',
+    note           TEXT,
+    request_id     VARCHAR(64) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_achievement_status_events_achievement
+    ON achievement_status_events (achievement_id, created_at);
+
+-- Backfill prestasi lama yang belum punya event dari kolom achievement_references
+-- (gen_random_uuid bawaan PostgreSQL 13+)
+CREATE TEMP TABLE backfill_refs ON COMMIT DROP AS
+SELECT ar.* FROM achievement_references ar
+WHERE NOT EXISTS (SELECT 1 FROM achievement_status_events e WHERE e.achievement_id = ar.id);
+
+INSERT INTO achievement_status_events (id, achievement_id, old_status, new_status, actor_user_id, actor_role, note, created_at)
+SELECT gen_random_uuid(), r.id, NULL, 'draft', s.user_id, 'student', 'Backfill migrasi', r.created_at
+FROM backfill_refs r JOIN students s ON s.id = r.student_id;
+
+INSERT INTO achievement_status_events (id, achievement_id, old_status, new_status, actor_user_id, actor_role, note, created_at)
+SELECT gen_random_uuid(), r.id, 'draft', 'submitted', s.user_id, 'student', 'Backfill migrasi', r.submitted_at
+FROM backfill_refs r JOIN students s ON s.id = r.student_id
+WHERE r.submitted_at IS NOT NULL;
+
+INSERT INTO achievement_status_events (id, achievement_id, old_status, new_status, actor_user_id, actor_role, note, created_at)
+SELECT gen_random_uuid(), r.id, 'submitted', r.status, r.verified_by, 'lecturer',
+       COALESCE(r.rejection_note, 'Backfill migrasi'), r.verified_at
+FROM backfill_refs r
+WHERE r.verified_at IS NOT NULL AND r.status IN ('verified', 'rejected');
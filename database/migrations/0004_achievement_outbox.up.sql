@@ -0,0 +1,16 @@
+-- Transactional outbox Postgres -> Mongo (lihat service/achievement_outbox.go).
+-- achievement_id sengaja tanpa FK: NULL untuk kompensasi create, dan baris dihapus saat purge.
+CREATE TABLE IF NOT EXISTS achievement_outbox (
+    id                   UUID PRIMARY KEY,
+    achievement_id       UUID,
+    mongo_achievement_id VARCHAR(24) NOT NULL,
+    operation            VARCHAR(30) NOT NULL,
+    attempts             INTEGER NOT NULL DEFAULT 0,
+    last_error           TEXT,
+    next_attempt_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at         TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_due
+    ON achievement_outbox (next_attempt_at) WHERE processed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_achievement_id ON achievement_outbox (achievement_id);
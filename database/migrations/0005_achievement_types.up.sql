@@ -0,0 +1,11 @@
+-- Registry tipe prestasi; details_schema = JSON Schema untuk field details di Mongo
+CREATE TABLE IF NOT EXISTS achievement_types (
+    id             UUID PRIMARY KEY,
+    code           VARCHAR(50) NOT NULL UNIQUE,
+    name           VARCHAR(100) NOT NULL,
+    description    TEXT,
+    details_schema JSONB NOT NULL DEFAULT '{"type": "object"}',
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+-- Aturan poin prestasi; kondisi NULL = cocok dengan nilai apa pun
+CREATE TABLE IF NOT EXISTS scoring_rules (
+    id               UUID PRIMARY KEY,
+    achievement_type VARCHAR(50) NOT NULL,
+    level            VARCHAR(50),
+    rank_min         INTEGER,
+    rank_max         INTEGER,
+    participation    VARCHAR(20),
+    points           INTEGER NOT NULL,
+    priority         INTEGER NOT NULL DEFAULT 0,
+    description      TEXT,
+    is_active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (rank_min IS NULL OR rank_max IS NULL OR rank_min <= rank_max)
+);
+CREATE INDEX IF NOT EXISTS idx_scoring_rules_type ON scoring_rules (achievement_type) WHERE is_active;
@@ -0,0 +1 @@
+ALTER TABLE achievement_outbox DROP COLUMN IF EXISTS payload;
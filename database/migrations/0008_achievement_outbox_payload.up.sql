@@ -0,0 +1,2 @@
+-- Data tambahan untuk operasi outbox (mis. poin & scoring hasil verifikasi dosen)
+ALTER TABLE achievement_outbox ADD COLUMN IF NOT EXISTS payload JSONB;
@@ -0,0 +1,337 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Nama role default (dipakai juga untuk resolusi aktor)
+const (
+	RoleAdmin    = "Admin"
+	RoleStudent  = "Mahasiswa"
+	RoleLecturer = "Dosen Wali"
+)
+
+type seedPermission struct {
+	Name        string
+	Resource    string
+	Action      string
+	Description string
+}
+
+var defaultPermissions = []seedPermission{
+	{"user:read", "user", "read", "Melihat data user"},
+	{"user:create", "user", "create", "Membuat user"},
+	{"user:update", "user", "update", "Mengubah user"},
+	{"user:delete", "user", "delete", "Menghapus user"},
+	{"user:assign_role", "user", "assign_role", "Mengubah role user"},
+	{"student:create", "student", "create", "Menambah data mahasiswa"},
+	{"student:update", "student", "update", "Mengubah data & dosen wali mahasiswa"},
+	{"lecturer:create", "lecturer", "create", "Menambah data dosen"},
+	{"lecturer:update", "lecturer", "update", "Mengubah data dosen"},
+	{"report:read", "report", "read", "Melihat laporan & statistik"},
+	{"achievement:create", "achievement", "create", "Membuat prestasi"},
+	{"achievement:read", "achievement", "read", "Melihat prestasi"},
+	{"achievement:update", "achievement", "update", "Mengubah prestasi"},
+	{"achievement:delete", "achievement", "delete", "Menghapus prestasi"},
+	{"achievement:verify", "achievement", "verify", "Memverifikasi / menolak prestasi"},
+	{"achievement:trash", "achievement", "trash", "Melihat, memulihkan, dan menghapus permanen prestasi terhapus"},
+	{"role:manage", "role", "manage", "Mengelola role dan permission milik role"},
+	{"permission:manage", "permission", "manage", "Mengelola master permission"},
+	{"achievement_type:manage", "achievement_type", "manage", "Mengelola tipe prestasi & schema details"},
+	{"scoring_rule:manage", "scoring_rule", "manage", "Mengelola aturan perhitungan poin prestasi"},
+}
+
+// Permission per role default. Admin selalu mendapat semua permission.
+var defaultRolePermissions = map[string][]string{
+	RoleStudent:  {"achievement:create", "achievement:read", "achievement:update", "achievement:delete"},
+	RoleLecturer: {"achievement:read", "achievement:verify", "report:read"},
+}
+
+// Role yang memegang permission ini dianggap role admin (termasuk role admin yang diganti nama /
+// dibuat sendiri) dan ikut mendapat permission khusus admin yang baru ditambahkan
+const adminMarkerPermission = "user:assign_role"
+
+var defaultRoleDescriptions = map[string]string{
+	RoleAdmin:    "Administrator sistem",
+	RoleStudent:  "Mahasiswa pelapor prestasi",
+	RoleLecturer: "Dosen wali yang memverifikasi prestasi",
+}
+
+// SeedDefaultRoles memastikan role & permission default ada (idempotent, aman dijalankan berulang).
+// Hanya menambah yang belum ada, tidak menimpa perubahan yang dibuat admin.
+func SeedDefaultRoles() {
+	if err := seedDefaultRoles(DB); err != nil {
+		log.Println("Peringatan: Gagal seed role default:", err)
+	}
+}
+
+func seedDefaultRoles(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	permissionIDs := make(map[string]uuid.UUID)
+	newPermissions := make(map[string]bool)
+	for _, p := range defaultPermissions {
+		id, created, err := upsertByName(tx, "permissions", p.Name, func(id uuid.UUID) error {
+			_, err := tx.Exec(`
+				INSERT INTO permissions (id, name, resource, action, description)
+				VALUES ($1, $2, $3, $4, $5)
+			`, id, p.Name, p.Resource, p.Action, p.Description)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		permissionIDs[p.Name] = id
+		newPermissions[p.Name] = created
+	}
+
+	for _, roleName := range []string{RoleAdmin, RoleStudent, RoleLecturer} {
+		roleID, roleCreated, err := upsertByName(tx, "roles", roleName, func(id uuid.UUID) error {
+			_, err := tx.Exec(`
+				INSERT INTO roles (id, name, description, created_at)
+				VALUES ($1, $2, $3, NOW())
+			`, id, roleName, defaultRoleDescriptions[roleName])
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		perms := defaultRolePermissions[roleName]
+		if roleName == RoleAdmin {
+			perms = nil
+			for _, p := range defaultPermissions {
+				perms = append(perms, p.Name)
+			}
+		}
+		for _, name := range perms {
+			// Role lama hanya mendapat permission yang baru dibuat,
+			// agar permission yang sengaja dicabut admin tidak kembali lagi
+			if !roleCreated && !newPermissions[name] {
+				continue
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO role_permissions (role_id, permission_id)
+				VALUES ($1, $2)
+				ON CONFLICT (role_id, permission_id) DO NOTHING
+			`, roleID, permissionIDs[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range defaultPermissions {
+		if !newPermissions[p.Name] || !isAdminOnlyPermission(p.Name) {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT role_id, $1 FROM role_permissions WHERE permission_id = $2
+			ON CONFLICT (role_id, permission_id) DO NOTHING
+		`, permissionIDs[p.Name], permissionIDs[adminMarkerPermission]); err != nil {
+			return err
+		}
+	}
+
+	if err := seedAchievementTypes(tx); err != nil {
+		return err
+	}
+	if err := seedScoringRules(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isAdminOnlyPermission: Permission yang secara default hanya dimiliki Admin
+func isAdminOnlyPermission(name string) bool {
+	for _, perms := range defaultRolePermissions {
+		for _, p := range perms {
+			if p == name {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type seedAchievementType struct {
+	Code          string
+	Name          string
+	Description   string
+	DetailsSchema string
+}
+
+// Tipe prestasi default beserta JSON Schema details. "other" untuk prestasi yang belum punya tipe khusus.
+var defaultAchievementTypes = []seedAchievementType{
+	{"competition", "Kompetisi", "Lomba / kompetisi akademik maupun non-akademik", `{
+		"type": "object",
+		"required": ["competition_name", "level", "rank", "event_date"],
+		"additionalProperties": false,
+		"properties": {
+			"competition_name": {"type": "string", "title": "Nama Kompetisi", "minLength": 3, "maxLength": 200},
+			"level": {"type": "string", "title": "Tingkat", "enum": ["local", "regional", "national", "international"]},
+			"rank": {"type": "integer", "title": "Peringkat", "minimum": 1},
+			"participation": {"type": "string", "title": "Partisipasi", "enum": ["individual", "team"]},
+			"medal_type": {"type": "string", "title": "Medali", "enum": ["gold", "silver", "bronze"]},
+			"organizer": {"type": "string", "title": "Penyelenggara", "maxLength": 200},
+			"event_date": {"type": "string", "title": "Tanggal Kegiatan", "format": "date"},
+			"location": {"type": "string", "title": "Lokasi", "maxLength": 200}
+		}
+	}`},
+	{"publication", "Publikasi", "Publikasi ilmiah (jurnal, konferensi, buku)", `{
+		"type": "object",
+		"required": ["publication_type", "publication_title", "authors", "publisher", "publication_date"],
+		"additionalProperties": false,
+		"properties": {
+			"publication_type": {"type": "string", "title": "Jenis Publikasi", "enum": ["journal", "conference", "book"]},
+			"publication_title": {"type": "string", "title": "Judul Publikasi", "minLength": 3, "maxLength": 300},
+			"authors": {"type": "array", "title": "Penulis", "minItems": 1, "items": {"type": "string", "minLength": 1}},
+			"publisher": {"type": "string", "title": "Penerbit", "maxLength": 200},
+			"issn": {"type": "string", "title": "ISSN", "pattern": "^[0-9]{4}-[0-9]{3}[0-9X]$"},
+			"doi": {"type": "string", "title": "DOI", "pattern": "^10\\.[0-9]{4,9}/\\S+$"},
+			"publication_date": {"type": "string", "title": "Tanggal Terbit", "format": "date"}
+		}
+	}`},
+	{"organization", "Organisasi", "Kepengurusan organisasi / kepanitiaan", `{
+		"type": "object",
+		"required": ["organization_name", "position", "period_start"],
+		"additionalProperties": false,
+		"properties": {
+			"organization_name": {"type": "string", "title": "Nama Organisasi", "minLength": 2, "maxLength": 200},
+			"position": {"type": "string", "title": "Jabatan", "maxLength": 100},
+			"period_start": {"type": "string", "title": "Mulai", "format": "date"},
+			"period_end": {"type": "string", "title": "Selesai", "format": "date"}
+		}
+	}`},
+	{"certification", "Sertifikasi", "Sertifikasi kompetensi / profesi", `{
+		"type": "object",
+		"required": ["certification_name", "issued_by", "issue_date"],
+		"additionalProperties": false,
+		"properties": {
+			"certification_name": {"type": "string", "title": "Nama Sertifikasi", "minLength": 2, "maxLength": 200},
+			"issued_by": {"type": "string", "title": "Penerbit", "maxLength": 200},
+			"certification_number": {"type": "string", "title": "Nomor Sertifikat", "maxLength": 100},
+			"issue_date": {"type": "string", "title": "Tanggal Terbit", "format": "date"},
+			"valid_until": {"type": "string", "title": "Berlaku Sampai", "format": "date"}
+		}
+	}`},
+	{"other", "Lainnya", "Prestasi lain tanpa format details khusus", `{"type": "object"}`},
+}
+
+// Schema default versi sebelumnya per tipe. Tipe yang schema-nya masih persis salah satu versi ini
+// (belum diubah admin) diperbarui ke schema default terbaru saat seed.
+var supersededAchievementTypeSchemas = map[string][]string{
+	// Sebelum field participation (aturan poin tim) ditambahkan
+	"competition": {`{
+		"type": "object",
+		"required": ["competition_name", "level", "rank", "event_date"],
+		"additionalProperties": false,
+		"properties": {
+			"competition_name": {"type": "string", "title": "Nama Kompetisi", "minLength": 3, "maxLength": 200},
+			"level": {"type": "string", "title": "Tingkat", "enum": ["local", "regional", "national", "international"]},
+			"rank": {"type": "integer", "title": "Peringkat", "minimum": 1},
+			"medal_type": {"type": "string", "title": "Medali", "enum": ["gold", "silver", "bronze"]},
+			"organizer": {"type": "string", "title": "Penyelenggara", "maxLength": 200},
+			"event_date": {"type": "string", "title": "Tanggal Kegiatan", "format": "date"},
+			"location": {"type": "string", "title": "Lokasi", "maxLength": 200}
+		}
+	}`},
+}
+
+// seedAchievementTypes menambah tipe default yang belum ada dan memperbarui schema tipe default
+// yang masih memakai schema versi lama (schema yang sudah diubah admin tidak ditimpa)
+func seedAchievementTypes(tx *sql.Tx) error {
+	for _, t := range defaultAchievementTypes {
+		if _, err := tx.Exec(`
+			INSERT INTO achievement_types (id, code, name, description, details_schema, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, TRUE, NOW(), NOW())
+			ON CONFLICT (code) DO UPDATE SET details_schema = EXCLUDED.details_schema, updated_at = NOW()
+			WHERE achievement_types.details_schema = ANY($6::jsonb[])
+		`, uuid.New(), t.Code, t.Name, t.Description, t.DetailsSchema, pq.Array(supersededAchievementTypeSchemas[t.Code])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type seedScoringRule struct {
+	AchievementType string
+	Level           *string
+	RankMin         *int
+	RankMax         *int
+	Participation   *string
+	Points          int
+	Description     string
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+// Aturan poin default: kompetisi berdasarkan tingkat & peringkat (tim mendapat poin sedikit lebih kecil
+// per anggota), tipe lain poin tetap. Admin bisa mengubahnya lewat /scoring-rules.
+var defaultScoringRules = func() []seedScoringRule {
+	rules := []seedScoringRule{
+		{"publication", nil, nil, nil, nil, 25, "Publikasi ilmiah"},
+		{"organization", nil, nil, nil, nil, 10, "Kepengurusan organisasi"},
+		{"certification", nil, nil, nil, nil, 15, "Sertifikasi kompetensi"},
+		{"other", nil, nil, nil, nil, 5, "Prestasi lainnya"},
+	}
+	levels := []struct {
+		code  string
+		base  int
+		label string
+	}{{"international", 50, "internasional"}, {"national", 30, "nasional"}, {"regional", 20, "regional"}, {"local", 10, "lokal"}}
+	for _, l := range levels {
+		rules = append(rules,
+			seedScoringRule{"competition", strPtr(l.code), intPtr(1), intPtr(1), nil, l.base, "Juara 1 kompetisi " + l.label},
+			seedScoringRule{"competition", strPtr(l.code), intPtr(2), intPtr(3), nil, l.base * 7 / 10, "Juara 2-3 kompetisi " + l.label},
+			seedScoringRule{"competition", strPtr(l.code), nil, nil, nil, l.base * 4 / 10, "Peserta / peringkat lain kompetisi " + l.label},
+			seedScoringRule{"competition", strPtr(l.code), intPtr(1), intPtr(3), strPtr("team"), l.base * 6 / 10, "Juara 1-3 kompetisi " + l.label + " (tim)"},
+		)
+	}
+	return rules
+}()
+
+// seedScoringRules hanya mengisi aturan default jika tabel masih kosong (aturan buatan admin tidak disentuh)
+func seedScoringRules(tx *sql.Tx) error {
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM scoring_rules").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	for _, r := range defaultScoringRules {
+		if _, err := tx.Exec(`
+			INSERT INTO scoring_rules (id, achievement_type, level, rank_min, rank_max, participation, points, priority,
+				description, is_active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, TRUE, NOW(), NOW())
+		`, uuid.New(), r.AchievementType, r.Level, r.RankMin, r.RankMax, r.Participation, r.Points, r.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertByName mengembalikan id baris dengan nama tersebut, atau membuatnya jika belum ada.
+// created bernilai true jika baris baru dibuat.
+func upsertByName(tx *sql.Tx, table, name string, insert func(id uuid.UUID) error) (id uuid.UUID, created bool, err error) {
+	err = tx.QueryRow("SELECT id FROM "+table+" WHERE name = $1", name).Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return uuid.Nil, false, err
+	}
+	id = uuid.New()
+	return id, true, insert(id)
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSupersededAchievementTypeSchemas(t *testing.T) {
+	current := map[string]string{}
+	for _, at := range defaultAchievementTypes {
+		current[at.Code] = at.DetailsSchema
+	}
+	for code, schemas := range supersededAchievementTypeSchemas {
+		latest, ok := current[code]
+		if !ok {
+			t.Errorf("%s: bukan tipe default", code)
+			continue
+		}
+		var want interface{}
+		if err := json.Unmarshal([]byte(latest), &want); err != nil {
+			t.Fatalf("%s: schema default tidak valid: %v", code, err)
+		}
+		for _, old := range schemas {
+			var got interface{}
+			if err := json.Unmarshal([]byte(old), &got); err != nil {
+				t.Errorf("%s: schema lama tidak valid: %v", code, err)
+			}
+			if reflect.DeepEqual(got, want) {
+				t.Errorf("%s: schema lama sama dengan schema terbaru", code)
+			}
+		}
+	}
+}
+
+func TestIsAdminOnlyPermission(t *testing.T) {
+	for _, name := range []string{"role:manage", "permission:manage", adminMarkerPermission} {
+		if !isAdminOnlyPermission(name) {
+			t.Errorf("%s seharusnya khusus admin", name)
+		}
+	}
+	for _, name := range []string{"achievement:read", "achievement:verify"} {
+		if isAdminOnlyPermission(name) {
+			t.Errorf("%s juga dimiliki role non-admin", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache: Cache in-memory sederhana dengan masa berlaku per item (aman untuk goroutine)
+type TTLCache[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]cacheEntry[V]
+}
+
+func NewTTLCache[K comparable, V any]() *TTLCache[K, V] {
+	return &TTLCache[K, V]{items: make(map[K]cacheEntry[V])}
+}
+
+// Get mengembalikan value dan true jika key ada dan belum kadaluwarsa
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	entry, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Bersihkan item kadaluwarsa sesekali agar map tidak terus membesar
+	if len(c.items) > 0 && len(c.items)%1024 == 0 {
+		now := time.Now()
+		for k, e := range c.items {
+			if now.After(e.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+	}
+	c.items[key] = cacheEntry[V]{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// Clear menghapus seluruh isi cache
+func (c *TTLCache[K, V]) Clear() {
+	c.mu.Lock()
+	c.items = make(map[K]cacheEntry[V])
+	c.mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldChange: Satu perubahan field hasil DiffJSON
+type FieldChange struct {
+	Path string      `json:"path"` // contoh: "title", "details.rank", "tags[1]"
+	Type string      `json:"type"` // added / removed / changed
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffJSON membandingkan dua value (struct/map) berdasarkan representasi JSON-nya
+// dan mengembalikan daftar perubahan per field (rekursif ke object & array).
+func DiffJSON(oldValue, newValue interface{}) ([]FieldChange, error) {
+	a, err := toJSONValue(oldValue)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toJSONValue(newValue)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	diffValue("", a, b, &changes)
+	return changes, nil
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+func diffValue(path string, a, b interface{}, changes *[]FieldChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffMap(path, av, bv, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffSlice(path, av, bv, changes)
+			return
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	switch {
+	case a == nil:
+		*changes = append(*changes, FieldChange{Path: path, Type: "added", New: b})
+	case b == nil:
+		*changes = append(*changes, FieldChange{Path: path, Type: "removed", Old: a})
+	default:
+		*changes = append(*changes, FieldChange{Path: path, Type: "changed", Old: a, New: b})
+	}
+}
+
+func diffMap(path string, a, b map[string]interface{}, changes *[]FieldChange) {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		child := k
+		if path != "" {
+			child = path + "." + k
+		}
+		diffValue(child, a[k], b[k], changes)
+	}
+}
+
+func diffSlice(path string, a, b []interface{}, changes *[]FieldChange) {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		child := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			*changes = append(*changes, FieldChange{Path: child, Type: "added", New: b[i]})
+		case i >= len(b):
+			*changes = append(*changes, FieldChange{Path: child, Type: "removed", Old: a[i]})
+		default:
+			diffValue(child, a[i], b[i], changes)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package helper
+
+import "testing"
+
+func TestDiffJSON_NestedFields(t *testing.T) {
+	oldValue := map[string]interface{}{
+		"title":   "Lomba A",
+		"points":  10,
+		"tags":    []string{"nasional", "robotik"},
+		"details": map[string]interface{}{"rank": 2, "organizer": "Kemdikbud"},
+	}
+	newValue := map[string]interface{}{
+		"title":   "Lomba A",
+		"points":  20,
+		"tags":    []string{"nasional", "ai", "tim"},
+		"details": map[string]interface{}{"rank": 1},
+	}
+
+	changes, err := DiffJSON(oldValue, newValue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"details.organizer": "removed",
+		"details.rank":      "changed",
+		"points":            "changed",
+		"tags[1]":           "changed",
+		"tags[2]":           "added",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for _, ch := range changes {
+		if want[ch.Path] != ch.Type {
+			t.Fatalf("unexpected change %s (%s)", ch.Path, ch.Type)
+		}
+	}
+}
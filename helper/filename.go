@@ -0,0 +1,64 @@
+package helper
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxFileNameBytes = 200
+
+// SanitizeFileName membersihkan nama file dari client sebelum disimpan / dikirim di Content-Disposition:
+// path dibuang (termasuk "C:\..."), karakter kontrol & karakter yang bermasalah di filesystem / header
+// diganti "_", spasi berlebih dirapikan, dan panjang dibatasi dengan ekstensi tetap dipertahankan.
+// Ekstensi disesuaikan dengan ext (mis. ".pdf") jika ext tidak kosong dan ekstensi asli tidak ada di allowedExts.
+func SanitizeFileName(name, ext string, allowedExts ...string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	var b strings.Builder
+	lastUnderscore := false
+	for _, r := range name {
+		if r == utf8.RuneError || unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|;`, r) {
+			r = '_'
+		}
+		if unicode.IsSpace(r) {
+			r = ' '
+		}
+		if r == '_' && lastUnderscore {
+			continue
+		}
+		lastUnderscore = r == '_'
+		b.WriteRune(r)
+	}
+	name = strings.Join(strings.Fields(b.String()), " ")
+	name = strings.Trim(name, " ._")
+
+	base, currentExt := name, path.Ext(name)
+	if currentExt != "" {
+		base = strings.TrimSuffix(name, currentExt)
+	}
+	if ext != "" && !containsFold(allowedExts, currentExt) && !strings.EqualFold(currentExt, ext) {
+		base, currentExt = name, ext
+	}
+	if base == "" {
+		base = "file"
+	}
+
+	// Potong di batas rune agar tetap UTF-8 valid
+	maxBase := maxFileNameBytes - len(currentExt)
+	for len(base) > maxBase {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = base[:len(base)-size]
+	}
+	return strings.TrimRight(base, " .") + strings.ToLower(currentExt)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
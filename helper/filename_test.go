@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	cases := []struct {
+		name, ext string
+		allowed   []string
+		want      string
+	}{
+		{"sertifikat lomba.pdf", ".pdf", nil, "sertifikat lomba.pdf"},
+		{"../../etc/passwd", "", nil, "passwd"},
+		{`C:\Users\budi\Bukti Juara.PDF`, ".pdf", nil, "Bukti Juara.pdf"},
+		{"a\x00b\r\nc\"d<e>.pdf", ".pdf", nil, "a_b_c_d_e_.pdf"},
+		{"foto.jpeg", ".jpg", []string{".jpg", ".jpeg"}, "foto.jpeg"},
+		{"laporan.exe", ".pdf", nil, "laporan.exe.pdf"},
+		{"   ", ".png", nil, "file.png"},
+		{".pdf", ".pdf", nil, "pdf.pdf"},
+	}
+	for _, tc := range cases {
+		if got := SanitizeFileName(tc.name, tc.ext, tc.allowed...); got != tc.want {
+			t.Errorf("SanitizeFileName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	long := SanitizeFileName(strings.Repeat("é", 300)+".pdf", ".pdf")
+	if len(long) > maxFileNameBytes || !utf8.ValidString(long) || !strings.HasSuffix(long, ".pdf") {
+		t.Errorf("nama panjang tidak dipotong dengan benar: %d byte", len(long))
+	}
+}
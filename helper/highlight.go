@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchTerms memecah query full-text menjadi kata yang akan di-highlight
+// (frasa "..." dipecah per kata, kata negasi -kata diabaikan).
+func SearchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+		if word != "" {
+			terms = append(terms, strings.ToLower(word))
+		}
+	}
+	return terms
+}
+
+// Highlight mengembalikan potongan teks di sekitar kemunculan pertama salah satu term,
+// dengan setiap kemunculan dibungkus <em>...</em>. Teks di-escape HTML sehingga hanya tag <em> yang aktif.
+// ok = false jika tidak ada term yang cocok.
+func Highlight(text string, terms []string, maxLen int) (snippet string, ok bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lowercase mengubah panjang (karakter khusus): cocokkan tanpa case folding
+		lower = runes
+	}
+
+	type span struct{ start, end int }
+	var matches []span
+	for i := 0; i < len(lower); i++ {
+		for _, term := range terms {
+			t := []rune(term)
+			if len(t) == 0 || i+len(t) > len(lower) || string(lower[i:i+len(t)]) != term {
+				continue
+			}
+			// Hanya awal kata (mis. "juara" cocok dengan "juara1", tidak dengan "kejuaraan")
+			if i > 0 && (unicode.IsLetter(lower[i-1]) || unicode.IsNumber(lower[i-1])) {
+				continue
+			}
+			matches = append(matches, span{i, i + len(t)})
+			i += len(t) - 1
+			break
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	// Jendela teks di sekitar match pertama
+	start, end := 0, len(runes)
+	if len(runes) > maxLen {
+		start = matches[0].start - maxLen/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxLen
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxLen
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m.start < start || m.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:m.start])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		b.WriteString("</em>")
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
@@ -0,0 +1,35 @@
+package helper
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	terms := SearchTerms(`Juara "lomba robot" -nasional`)
+	if len(terms) != 3 || terms[0] != "juara" || terms[2] != "robot" {
+		t.Fatalf("unexpected terms: %v", terms)
+	}
+
+	got, ok := Highlight("Juara 1 Lomba Robot Tingkat Nasional", terms, 200)
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	want := "<em>Juara</em> 1 <em>Lomba</em> <em>Robot</em> Tingkat Nasional"
+	if got != want {
+		t.Fatalf("unexpected snippet:\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, ok := Highlight("Kejuaraan daerah", []string{"juara"}, 200); ok {
+		t.Fatalf("match in the middle of a word should not be highlighted")
+	}
+
+	long := "Deskripsi panjang sebelum kata kunci yang dicari berada di sini: robotika, lalu teks lanjutan yang cukup panjang"
+	got, ok = Highlight(long, []string{"robotika"}, 40)
+	if !ok || got[:3] != "…" {
+		t.Fatalf("expected truncated snippet with leading ellipsis, got %q", got)
+	}
+
+	got, ok = Highlight(`<b>Robot</b> & <img src=x onerror="alert(1)">`, []string{"robot"}, 200)
+	want = "&lt;b&gt;<em>Robot</em>&lt;/b&gt; &amp; &lt;img src=x onerror=&#34;alert(1)&#34;&gt;"
+	if !ok || got != want {
+		t.Fatalf("HTML in text must be escaped:\n got: %s\nwant: %s", got, want)
+	}
+}
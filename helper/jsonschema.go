@@ -0,0 +1,241 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// FieldError: Error validasi per field (path seperti "details.rank", "tags[0]")
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Schema: Subset JSON Schema (draft 2020-12) yang dipakai untuk details prestasi.
+// Keyword yang didukung: type, properties, required, additionalProperties, enum, minimum,
+// maximum, minLength, maxLength, pattern, format (date, date-time, email, uri), items,
+// minItems, maxItems. Keyword lain (title, description, ...) hanya informatif untuk frontend.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var schemaTypes = map[string]bool{"": true, "object": true, "array": true, "string": true, "integer": true, "number": true, "boolean": true}
+var schemaFormats = map[string]bool{"": true, "date": true, "date-time": true, "email": true, "uri": true}
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uriPattern   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://\S+$`)
+)
+
+// ParseSchema membaca dan memeriksa schema (type/format dikenal, pattern valid)
+func ParseSchema(raw []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("schema bukan JSON valid: %w", err)
+	}
+	if err := s.compile("$"); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if !schemaTypes[s.Type] {
+		return fmt.Errorf("%s: type %q tidak didukung", path, s.Type)
+	}
+	if !schemaFormats[s.Format] {
+		return fmt.Errorf("%s: format %q tidak didukung", path, s.Format)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: pattern tidak valid: %w", path, err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("%s.%s: schema kosong", path, name)
+		}
+		if err := prop.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	for _, name := range s.Required {
+		if s.Properties != nil {
+			if _, ok := s.Properties[name]; !ok {
+				return fmt.Errorf("%s: required %q tidak ada di properties", path, name)
+			}
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Validate memeriksa value (hasil json.Unmarshal ke interface{}) terhadap schema
+func (s *Schema) Validate(value interface{}, path string) []FieldError {
+	var errs []FieldError
+	s.validate(value, path, &errs)
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		fail("harus salah satu dari %v", s.Enum)
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("harus berupa object")
+			return
+		}
+		for _, name := range s.Required {
+			if v, ok := obj[name]; !ok || v == nil {
+				*errs = append(*errs, FieldError{Field: joinPath(path, name), Message: "wajib diisi"})
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			prop, known := s.Properties[k]
+			switch {
+			case known && obj[k] != nil:
+				prop.validate(obj[k], joinPath(path, k), errs)
+			case !known && s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				*errs = append(*errs, FieldError{Field: joinPath(path, k), Message: "field tidak dikenal"})
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			fail("harus berupa array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail("minimal %d item", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail("maksimal %d item", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("harus berupa string")
+			return
+		}
+		n := len([]rune(str))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("minimal %d karakter", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("maksimal %d karakter", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			fail("format tidak sesuai pola %s", s.Pattern)
+		}
+		if err := checkFormat(s.Format, str); err != nil {
+			fail("%s", err.Error())
+		}
+
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			fail("harus berupa angka")
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("harus bilangan bulat")
+			return
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("minimal %v", *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			fail("maksimal %v", *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("harus berupa boolean")
+		}
+	}
+}
+
+func checkFormat(format, value string) error {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return errors.New("harus tanggal dengan format YYYY-MM-DD")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return errors.New("harus tanggal-waktu RFC3339")
+		}
+	case "email":
+		if !emailPattern.MatchString(value) {
+			return errors.New("harus alamat email yang valid")
+		}
+	case "uri":
+		if !uriPattern.MatchString(value) {
+			return errors.New("harus URL yang valid")
+		}
+	}
+	return nil
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
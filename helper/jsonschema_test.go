@@ -0,0 +1,50 @@
+package helper
+
+import "testing"
+
+func TestSchemaValidate_CompetitionDetails(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["level", "rank", "event_date"],
+		"additionalProperties": false,
+		"properties": {
+			"level": {"type": "string", "enum": ["national", "international"]},
+			"rank": {"type": "integer", "minimum": 1},
+			"event_date": {"type": "string", "format": "date"},
+			"organizer": {"type": "string", "minLength": 3}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected schema error: %v", err)
+	}
+
+	valid := map[string]interface{}{"level": "national", "rank": float64(1), "event_date": "2026-05-01"}
+	if errs := schema.Validate(valid, "details"); len(errs) != 0 {
+		t.Fatalf("expected valid, got %+v", errs)
+	}
+
+	invalid := map[string]interface{}{"level": "galaxy", "rank": 1.5, "event_date": "01-05-2026", "junk": true}
+	errs := schema.Validate(invalid, "details")
+	want := map[string]bool{"details.level": true, "details.rank": true, "details.event_date": true, "details.junk": true}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), errs)
+	}
+	for _, e := range errs {
+		if !want[e.Field] {
+			t.Fatalf("unexpected error field %s", e.Field)
+		}
+	}
+
+	if errs := schema.Validate(map[string]interface{}{}, "details"); len(errs) != 3 {
+		t.Fatalf("expected 3 required errors, got %+v", errs)
+	}
+}
+
+func TestParseSchema_RejectsUnsupported(t *testing.T) {
+	if _, err := ParseSchema([]byte(`{"type": "tuple"}`)); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+	if _, err := ParseSchema([]byte(`{"type": "string", "pattern": "("}`)); err == nil {
+		t.Fatalf("expected error for invalid pattern")
+	}
+}
@@ -1,32 +1,28 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
-var refreshSecret = []byte("REFRESH_SECRET_KEY") // pindahkan ke ENV kalau mau
+// RefreshTokenTTL: Masa berlaku refresh token (7 hari)
+const RefreshTokenTTL = 7 * 24 * time.Hour
 
-func GenerateRefreshToken(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 hari
-		"type":    "refresh",
+// GenerateRefreshToken membuat refresh token acak (opaque).
+// Token asli hanya dikirim ke client, yang disimpan di database adalah hash-nya.
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(refreshSecret)
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func ValidateRefreshToken(tokenStr string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return refreshSecret, nil
-	})
-
-	if err != nil || !token.Valid {
-		return nil, err
-	}
-
-	return token.Claims.(jwt.MapClaims), nil
+// HashRefreshToken menghasilkan SHA-256 (hex) dari refresh token
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
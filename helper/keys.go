@@ -0,0 +1,352 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Status key di dalam keyset
+//   - active : bisa dipakai verifikasi, dan dipakai untuk sign jika kid == active_kid
+//   - verify : hanya untuk verifikasi token lama (masa transisi rotasi)
+//   - retired: token dengan kid ini ditolak
+type KeyStatus string
+
+const (
+	KeyStatusActive  KeyStatus = "active"
+	KeyStatusVerify  KeyStatus = "verify"
+	KeyStatusRetired KeyStatus = "retired"
+)
+
+// KeyConfig: Satu entry key di JWT_KEYS / JWT_KEYS_FILE
+type KeyConfig struct {
+	KID            string    `json:"kid"`
+	Alg            string    `json:"alg"` // HS256, RS256, EdDSA
+	Status         KeyStatus `json:"status"`
+	Secret         string    `json:"secret,omitempty"`      // HS256
+	SecretFile     string    `json:"secret_file,omitempty"` // HS256
+	PrivateKeyFile string    `json:"private_key_file,omitempty"`
+	PublicKeyFile  string    `json:"public_key_file,omitempty"` // Untuk key verify-only
+}
+
+// KeySetConfig: Format JSON untuk JWT_KEYS / JWT_KEYS_FILE
+//
+//	{
+//	  "active_kid": "2026-10",
+//	  "default_kid": "legacy",
+//	  "keys": [
+//	    {"kid": "2026-10", "alg": "RS256", "private_key_file": "keys/2026-10.pem"},
+//	    {"kid": "legacy", "alg": "HS256", "secret": "...", "status": "verify"}
+//	  ]
+//	}
+type KeySetConfig struct {
+	ActiveKID  string      `json:"active_kid"`
+	DefaultKID string      `json:"default_kid"` // Dipakai untuk token lama yang belum punya header kid
+	Keys       []KeyConfig `json:"keys"`
+}
+
+type SigningKey struct {
+	KID       string
+	Alg       string
+	Status    KeyStatus
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type KeySet struct {
+	activeKID  string
+	defaultKID string
+	keys       map[string]*SigningKey
+}
+
+var (
+	keySet     *KeySet
+	keySetOnce sync.Once
+)
+
+// InitKeySet memuat keyset dari ENV. Dipanggil sekali saat startup (main.go).
+func InitKeySet() error {
+	ks, err := LoadKeySetFromEnv()
+	if err != nil {
+		return err
+	}
+	keySet = ks
+	return nil
+}
+
+// Keys mengembalikan keyset global (dimuat dari ENV jika belum diinisialisasi)
+func Keys() *KeySet {
+	keySetOnce.Do(func() {
+		if keySet != nil {
+			return
+		}
+		ks, err := LoadKeySetFromEnv()
+		if err != nil {
+			log.Fatal("Gagal memuat JWT keyset:", err)
+		}
+		keySet = ks
+	})
+	return keySet
+}
+
+// LoadKeySetFromEnv membaca konfigurasi key dengan urutan:
+// JWT_KEYS_FILE (path JSON) -> JWT_KEYS (JSON inline) -> JWT_SECRET (satu key HS256)
+func LoadKeySetFromEnv() (*KeySet, error) {
+	var cfg KeySetConfig
+
+	switch {
+	case os.Getenv("JWT_KEYS_FILE") != "":
+		raw, err := os.ReadFile(os.Getenv("JWT_KEYS_FILE"))
+		if err != nil {
+			return nil, fmt.Errorf("baca JWT_KEYS_FILE: %w", err)
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("parse JWT_KEYS_FILE: %w", err)
+		}
+	case os.Getenv("JWT_KEYS") != "":
+		if err := json.Unmarshal([]byte(os.Getenv("JWT_KEYS")), &cfg); err != nil {
+			return nil, fmt.Errorf("parse JWT_KEYS: %w", err)
+		}
+	case os.Getenv("JWT_SECRET") != "":
+		kid := os.Getenv("JWT_KID")
+		if kid == "" {
+			kid = "default"
+		}
+		cfg = KeySetConfig{
+			ActiveKID:  kid,
+			DefaultKID: kid,
+			Keys:       []KeyConfig{{KID: kid, Alg: "HS256", Secret: os.Getenv("JWT_SECRET")}},
+		}
+	default:
+		// Tanpa konfigurasi: pakai key acak (hanya untuk development)
+		log.Println("Peringatan: JWT_SECRET / JWT_KEYS belum diset, memakai key acak (token invalid setelah restart)")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		cfg = KeySetConfig{
+			ActiveKID: "ephemeral",
+			Keys:      []KeyConfig{{KID: "ephemeral", Alg: "HS256", Secret: string(secret)}},
+		}
+	}
+
+	return NewKeySet(cfg)
+}
+
+func NewKeySet(cfg KeySetConfig) (*KeySet, error) {
+	ks := &KeySet{activeKID: cfg.ActiveKID, defaultKID: cfg.DefaultKID, keys: make(map[string]*SigningKey)}
+
+	for _, kc := range cfg.Keys {
+		if kc.KID == "" {
+			return nil, errors.New("setiap key wajib punya kid")
+		}
+		if _, dup := ks.keys[kc.KID]; dup {
+			return nil, fmt.Errorf("kid duplikat: %s", kc.KID)
+		}
+		key, err := loadSigningKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kc.KID, err)
+		}
+		ks.keys[kc.KID] = key
+	}
+
+	active, ok := ks.keys[ks.activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active_kid %q tidak ada di daftar key", ks.activeKID)
+	}
+	if active.Status != KeyStatusActive || active.signKey == nil {
+		return nil, fmt.Errorf("active_kid %q harus berstatus active dan punya private key/secret", ks.activeKID)
+	}
+	return ks, nil
+}
+
+func loadSigningKey(kc KeyConfig) (*SigningKey, error) {
+	key := &SigningKey{KID: kc.KID, Alg: kc.Alg, Status: kc.Status}
+	if key.Status == "" {
+		key.Status = KeyStatusActive
+	}
+	switch key.Status {
+	case KeyStatusActive, KeyStatusVerify, KeyStatusRetired:
+	default:
+		return nil, fmt.Errorf("status tidak dikenal: %s", key.Status)
+	}
+
+	switch kc.Alg {
+	case "HS256":
+		key.method = jwt.SigningMethodHS256
+		secret := []byte(kc.Secret)
+		if kc.SecretFile != "" {
+			raw, err := os.ReadFile(kc.SecretFile)
+			if err != nil {
+				return nil, err
+			}
+			secret = raw
+		}
+		if len(secret) == 0 {
+			return nil, errors.New("secret HS256 kosong")
+		}
+		key.signKey, key.verifyKey = secret, secret
+
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		if kc.PrivateKeyFile != "" {
+			raw, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(raw)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey, key.verifyKey = priv, &priv.PublicKey
+		} else if kc.PublicKeyFile != "" {
+			raw, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(raw)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = pub
+		} else {
+			return nil, errors.New("RS256 butuh private_key_file atau public_key_file")
+		}
+
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+		if kc.PrivateKeyFile != "" {
+			raw, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(raw)
+			if err != nil {
+				return nil, err
+			}
+			edPriv, ok := priv.(ed25519.PrivateKey)
+			if !ok {
+				return nil, errors.New("private key bukan Ed25519")
+			}
+			key.signKey, key.verifyKey = edPriv, edPriv.Public()
+		} else if kc.PublicKeyFile != "" {
+			raw, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseEdPublicKeyFromPEM(raw)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = pub
+		} else {
+			return nil, errors.New("EdDSA butuh private_key_file atau public_key_file")
+		}
+
+	default:
+		return nil, fmt.Errorf("algoritma tidak didukung: %s", kc.Alg)
+	}
+	return key, nil
+}
+
+// Sign menandatangani claims dengan key aktif dan menambahkan header kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := ks.keys[ks.activeKID]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.signKey)
+}
+
+// Parse memverifikasi token terhadap key sesuai header kid (key retired ditolak)
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			kid = ks.defaultKID
+		}
+		key, ok := ks.keys[kid]
+		if !ok || key.Status == KeyStatusRetired {
+			return nil, fmt.Errorf("kid tidak dikenal atau sudah retired: %q", kid)
+		}
+		// Cegah "algorithm confusion": alg di header harus sama dengan alg key
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("alg %s tidak cocok dengan key %s", t.Method.Alg(), kid)
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token tidak valid")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("gagal membaca data token")
+	}
+	return claims, nil
+}
+
+// --- JWKS ---
+
+// JWK: Public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan public key (RS256 & EdDSA) yang masih bisa dipakai verifikasi.
+// Key HS256 tidak pernah dipublikasikan.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		if key.Status == KeyStatusRetired {
+			continue
+		}
+		if jwk, ok := publicJWK(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func publicJWK(key *SigningKey) (JWK, bool) {
+	var pub crypto.PublicKey = key.verifyKey
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA", Kid: key.KID, Alg: key.Alg, Use: "sig",
+			N: base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP", Kid: key.KID, Alg: key.Alg, Use: "sig", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(k),
+		}, true
+	}
+	return JWK{}, false
+}
@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeEdKey(t *testing.T) string {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ed.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeySet_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey := KeyConfig{KID: "old", Alg: "HS256", Secret: "old-secret"}
+	oldSet, err := NewKeySet(KeySetConfig{ActiveKID: "old", Keys: []KeyConfig{oldKey}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := oldSet.Sign(jwt.MapClaims{"user_id": "u1"})
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	// Rotasi: key baru aktif (EdDSA), key lama hanya verify
+	oldKey.Status = KeyStatusVerify
+	newKey := KeyConfig{KID: "new", Alg: "EdDSA", PrivateKeyFile: writeEdKey(t)}
+	rotated, err := NewKeySet(KeySetConfig{ActiveKID: "new", Keys: []KeyConfig{newKey, oldKey}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := rotated.Parse(token); err != nil {
+		t.Fatalf("old token should still be valid: %v", err)
+	}
+
+	newToken, _ := rotated.Sign(jwt.MapClaims{"user_id": "u1"})
+	if _, err := rotated.Parse(newToken); err != nil {
+		t.Fatalf("new token should be valid: %v", err)
+	}
+	if jwks := rotated.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "new" {
+		t.Fatalf("jwks should only expose the EdDSA key, got %+v", jwks)
+	}
+
+	// Key lama dipensiunkan -> token lama ditolak
+	oldKey.Status = KeyStatusRetired
+	retired, _ := NewKeySet(KeySetConfig{ActiveKID: "new", Keys: []KeyConfig{newKey, oldKey}})
+	if _, err := retired.Parse(token); err == nil {
+		t.Fatalf("token signed with retired key should be rejected")
+	}
+}
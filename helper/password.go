@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,15 +20,34 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
+// AccessTokenTTL: Masa berlaku access token
+const AccessTokenTTL = time.Hour * 72
+
 // GenerateToken creates JWT with Role AND Permissions (FR-001)
-func GenerateToken(userID string, roleID string, permissions []string) (string, error) {
+// sessionID adalah family refresh token, dipakai saat logout untuk mencabut sesi yang sama
+func GenerateToken(userID string, roleID string, permissions []string, sessionID string) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
+		"jti":         uuid.NewString(), // ID unik token (untuk denylist saat logout)
+		"sid":         sessionID,
 		"user_id":     userID,
 		"role_id":     roleID,
 		"permissions": permissions, // Menyimpan permissions di token
-		"exp":         time.Now().Add(time.Hour * 72).Unix(),
+		"iat":         now.Unix(),
+		"iat_us":      now.UnixMicro(), // iat presisi mikrodetik, dibandingkan dengan waktu logout-all
+		"exp":         now.Add(AccessTokenTTL).Unix(),
+	}
+	// Sign dengan key aktif dari keyset (lihat helper/keys.go)
+	return Keys().Sign(claims)
+}
+
+// TokenIssuedAt: Waktu terbit token dari claim iat_us, atau iat (detik) untuk token lama
+func TokenIssuedAt(claims jwt.MapClaims) time.Time {
+	if us, ok := claims["iat_us"].(float64); ok {
+		return time.UnixMicro(int64(us))
+	}
+	if issuedAt, err := claims.GetIssuedAt(); err == nil && issuedAt != nil {
+		return issuedAt.Time
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// Ganti "RAHASIA_SUPER" dengan env variable di production
-	return token.SignedString([]byte("RAHASIA_SUPER"))
+	return time.Time{}
 }
\ No newline at end of file
@@ -0,0 +1,181 @@
+package helper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Validasi deklaratif lewat tag `validate` pada struct request, contoh:
+//
+//	Email  string    `json:"email" validate:"required,email,max=100"`
+//	RoleID uuid.UUID `json:"role_id" validate:"required,exists=roles"`
+//	Level  *string   `json:"level" validate:"omitempty,oneof=local national"`
+//
+// Rule bawaan: required, omitempty, email, min=N, max=N (panjang string / nilai angka / jumlah item),
+// maxbytes=N (panjang string dalam byte), oneof=a b c, notrim (spasi di awal/akhir string ikut dihitung,
+// mis. untuk password). Rule lain (mis. exists) didaftarkan lewat RegisterValidation.
+// Nama field di FieldError mengikuti tag json.
+
+// ValidationFunc: Rule custom. Mengembalikan pesan error ("" = valid); err untuk kegagalan sistem (mis. DB).
+type ValidationFunc func(value reflect.Value, param string) (message string, err error)
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   = map[string]ValidationFunc{}
+)
+
+// RegisterValidation mendaftarkan rule custom (dipanggil saat init package service)
+func RegisterValidation(name string, fn ValidationFunc) {
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+	customRules[name] = fn
+}
+
+// ValidateStruct memeriksa semua field ber-tag `validate` pada struct (atau pointer ke struct).
+// Per field hanya error pertama yang dilaporkan.
+func ValidateStruct(v interface{}) ([]FieldError, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ValidateStruct: butuh struct, bukan %s", rv.Kind())
+	}
+
+	var errs []FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		msg, err := validateField(rv.Field(i), strings.Split(tag, ","))
+		if err != nil {
+			return nil, err
+		}
+		if msg != "" {
+			errs = append(errs, FieldError{Field: jsonFieldName(field), Message: msg})
+		}
+	}
+	return errs, nil
+}
+
+func validateField(value reflect.Value, rules []string) (string, error) {
+	if value.IsZero() {
+		for _, rule := range rules {
+			if rule == "required" {
+				return "wajib diisi", nil
+			}
+		}
+		return "", nil // Kosong & tidak wajib: rule lain dilewati
+	}
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	trim := !containsString(rules, "notrim")
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		var msg string
+		var err error
+		switch name {
+		case "required", "omitempty", "notrim":
+			if name == "required" && trim && value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+				msg = "wajib diisi"
+			}
+		case "email":
+			if value.Kind() == reflect.String && !emailPattern.MatchString(value.String()) {
+				msg = "harus alamat email yang valid"
+			}
+		case "min", "max":
+			msg, err = checkBound(value, name, param, trim)
+		case "maxbytes":
+			msg, err = checkMaxBytes(value, param)
+		case "oneof":
+			options := strings.Fields(param)
+			if !containsString(options, fmt.Sprint(value.Interface())) {
+				msg = "harus salah satu dari: " + strings.Join(options, ", ")
+			}
+		default:
+			customRulesMu.RLock()
+			fn, ok := customRules[name]
+			customRulesMu.RUnlock()
+			if !ok {
+				return "", fmt.Errorf("rule validasi tidak dikenal: %s", name)
+			}
+			msg, err = fn(value, param)
+		}
+		if err != nil || msg != "" {
+			return msg, err
+		}
+	}
+	return "", nil
+}
+
+func checkBound(value reflect.Value, rule, param string, trim bool) (string, error) {
+	limit, err := strconv.Atoi(param)
+	if err != nil {
+		return "", fmt.Errorf("parameter %s tidak valid: %q", rule, param)
+	}
+
+	var n int
+	unit := ""
+	switch value.Kind() {
+	case reflect.String:
+		s := value.String()
+		if trim {
+			s = strings.TrimSpace(s)
+		}
+		n, unit = len([]rune(s)), " karakter"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n, unit = value.Len(), " item"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = int(value.Int())
+	default:
+		return "", nil
+	}
+
+	if rule == "min" && n < limit {
+		return fmt.Sprintf("minimal %d%s", limit, unit), nil
+	}
+	if rule == "max" && n > limit {
+		return fmt.Sprintf("maksimal %d%s", limit, unit), nil
+	}
+	return "", nil
+}
+
+// checkMaxBytes: Batas byte string tanpa trim (mis. bcrypt hanya memakai 72 byte pertama password)
+func checkMaxBytes(value reflect.Value, param string) (string, error) {
+	limit, err := strconv.Atoi(param)
+	if err != nil {
+		return "", fmt.Errorf("parameter maxbytes tidak valid: %q", param)
+	}
+	if value.Kind() == reflect.String && len(value.String()) > limit {
+		return fmt.Sprintf("maksimal %d byte", limit), nil
+	}
+	return "", nil
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
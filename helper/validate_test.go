@@ -0,0 +1,95 @@
+package helper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type validateSample struct {
+	Username string     `json:"username" validate:"required,min=3,max=10"`
+	Email    string     `json:"email" validate:"required,email"`
+	RoleID   uuid.UUID  `json:"role_id" validate:"required,known"`
+	Level    *string    `json:"level" validate:"omitempty,oneof=local national"`
+	Tags     []string   `json:"tags" validate:"max=2"`
+	Advisor  *uuid.UUID `json:"advisor_id" validate:"omitempty,known"`
+	Note     string     `json:"note"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	known := uuid.New()
+	RegisterValidation("known", func(v reflect.Value, _ string) (string, error) {
+		if v.Interface().(uuid.UUID) != known {
+			return "tidak ditemukan", nil
+		}
+		return "", nil
+	})
+
+	national := "national"
+	valid := validateSample{Username: "budi", Email: "budi@kampus.ac.id", RoleID: known, Level: &national}
+	if errs, err := ValidateStruct(&valid); err != nil || len(errs) != 0 {
+		t.Fatalf("expected valid, got %+v (err %v)", errs, err)
+	}
+
+	galaxy := "galaxy"
+	other := uuid.New()
+	invalid := validateSample{
+		Username: "  ", Email: "bukan-email", RoleID: other, Level: &galaxy,
+		Tags: []string{"a", "b", "c"}, Advisor: &other,
+	}
+	errs, err := ValidateStruct(invalid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"username":   "wajib diisi",
+		"email":      "harus alamat email yang valid",
+		"role_id":    "tidak ditemukan",
+		"level":      "harus salah satu dari: local, national",
+		"tags":       "maksimal 2 item",
+		"advisor_id": "tidak ditemukan",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Field] != e.Message {
+			t.Fatalf("field %s: expected %q, got %q", e.Field, want[e.Field], e.Message)
+		}
+	}
+
+	errs, _ = ValidateStruct(validateSample{})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 required errors, got %+v", errs)
+	}
+}
+
+type passwordSample struct {
+	Password string `json:"password" validate:"required,notrim,min=8,maxbytes=72"`
+}
+
+func TestValidatePasswordBytes(t *testing.T) {
+	cases := map[string]string{
+		"rahasia123":                    "",
+		"  abc  ":                       "minimal 8 karakter",
+		"   abcde":                      "", // Spasi termasuk password
+		strings.Repeat("a", 72):         "",
+		strings.Repeat("é", 37):         "maksimal 72 byte", // 37 karakter, 74 byte
+		strings.Repeat("a", 70) + "   ": "maksimal 72 byte",
+	}
+	for password, want := range cases {
+		errs, err := ValidateStruct(passwordSample{Password: password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if got != want {
+			t.Errorf("password %q: expected %q, got %q", password, want, got)
+		}
+	}
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
-	
+	"context"
 	"log"
 	"os"
+	"time"
 
+	"project-uas/app/response"
+	"project-uas/app/service"
 	"project-uas/database"
+	"project-uas/helper"
+	"project-uas/preview"
 	"project-uas/route"
+	"project-uas/scanner"
+	"project-uas/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/swagger"
 	"github.com/joho/godotenv"
 
@@ -31,11 +39,61 @@ func main() {
 	// Load env
 	_ = godotenv.Load()
 
+	// Subcommand CLI (mis. `go run . reconcile`), lihat commands.go
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
+	// JWT Keyset (lihat helper/keys.go)
+	if err := helper.InitKeySet(); err != nil {
+		log.Fatal("Gagal memuat JWT keyset:", err)
+	}
+
+	// Storage lampiran (local / S3-compatible), lihat storage/storage.go
+	if err := storage.Init(); err != nil {
+		log.Fatal("Gagal menyiapkan storage:", err)
+	}
+
+	// Scanner malware lampiran (ClamAV / none), lihat scanner/scanner.go
+	if err := scanner.Init(); err != nil {
+		log.Fatal("Gagal menyiapkan scanner malware:", err)
+	}
+
+	// Thumbnail / preview lampiran (PREVIEW_MAX_SIZE, PREVIEW_PDF_RENDERER), lihat preview/preview.go
+	if err := preview.Init(); err != nil {
+		log.Fatal("Gagal menyiapkan preview lampiran:", err)
+	}
+
 	// DB
 	database.ConnectDB()
 
+	// Skema harus sudah up to date (`go run . migrate up`), termasuk index Mongo
+	if err := database.CheckMigrations(database.DB); err != nil {
+		log.Fatal("Skema database belum siap: ", err)
+	}
+
+	// Seed role & permission default (idempotent)
+	database.SeedDefaultRoles()
+
+	// Worker outbox Postgres -> Mongo (lihat service/achievement_outbox.go)
+	service.StartAchievementOutboxWorker(context.Background(), 15*time.Second)
+
+	// Hapus permanen trash prestasi setelah masa retensi (ACHIEVEMENT_TRASH_RETENTION_DAYS)
+	service.StartAchievementPurgeWorker(context.Background(), time.Hour)
+
+	// Pindai ulang lampiran yang belum dipindai (scanner sempat tidak tersedia / lampiran lama)
+	service.StartAttachmentScanWorker(context.Background(), time.Minute)
+
+	// Thumbnail gambar & halaman pertama PDF untuk lampiran yang sudah lolos pindai
+	service.StartAttachmentPreviewWorker(context.Background(), time.Minute)
+
+	// Bersihkan denylist access token yang sudah kadaluwarsa
+	service.StartRevokedTokenCleanupWorker(context.Background(), time.Hour)
+
 	// Fiber
-	app := fiber.New()
+	// Semua error handler (response.Error, *fiber.Error) jadi satu bentuk JSON
+	// BodyLimit mengikuti batas lampiran terbesar (ATTACHMENT_ALLOWED_TYPES)
+	app := fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler, BodyLimit: service.AttachmentBodyLimit()})
 
 	// ✅ CORS (WAJIB SEBELUM ROUTE)
 	app.Use(cors.New(cors.Config{
@@ -44,8 +102,13 @@ func main() {
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 	}))
 
+	// Request ID (dipakai juga di event log status prestasi)
+	app.Use(requestid.New())
+
 	// Logger
-	app.Use(logger.New())
+	app.Use(logger.New(logger.Config{
+		Format: "[${time}] ${locals:requestid} ${status} - ${latency} ${method} ${path}\n",
+	}))
 
 	// Swagger
 	app.Get("/swagger/*", swagger.HandlerDefault)
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"project-uas/app/model"
+	"project-uas/app/repository"
+	"project-uas/app/response"
+	"project-uas/database"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Aksi terhadap achievement yang dicek oleh RequireAchievementAccess
+const (
+	AchievementView   = "view"   // Detail, history
+	AchievementEdit   = "edit"   // Update, delete, submit, upload attachment (pemilik)
+	AchievementVerify = "verify" // Verify, reject (dosen wali)
+)
+
+// RequireAchievementAccess: Policy berbasis relasi untuk endpoint /achievements/:id
+//   - Mahasiswa : hanya prestasi miliknya sendiri (view & edit)
+//   - Dosen Wali: hanya prestasi mahasiswa bimbingannya (view & verify)
+//   - Admin     : semua aksi
+//   - Lainnya   : ditolak
+//
+// Reference yang sudah dimuat disimpan ke Locals("achievement").
+func RequireAchievementAccess(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return response.BadRequest("ID prestasi tidak valid")
+		}
+
+		ref, err := repository.GetAchievementReferenceByID(database.DB, id)
+		if err != nil {
+			return response.NotFound("Prestasi tidak ditemukan")
+		}
+
+		actor, _ := c.Locals("actor").(*model.Actor)
+		allowed, err := canAccessAchievement(actor, ref.StudentID, action)
+		if err != nil {
+			return response.Internal("Gagal memeriksa hak akses prestasi", err)
+		}
+		if !allowed {
+			return response.Forbidden("Forbidden: Anda tidak memiliki akses ke prestasi ini")
+		}
+
+		c.Locals("achievement", ref)
+		return c.Next()
+	}
+}
+
+func canAccessAchievement(actor *model.Actor, ownerStudentID uuid.UUID, action string) (bool, error) {
+	if actor == nil {
+		return false, nil
+	}
+
+	switch actor.Kind {
+	case model.ActorAdmin:
+		return true, nil
+
+	case model.ActorStudent:
+		return actor.IsStudentOwner(ownerStudentID) && (action == AchievementView || action == AchievementEdit), nil
+
+	case model.ActorLecturer:
+		if action != AchievementView && action != AchievementVerify {
+			return false, nil
+		}
+		owner, err := repository.GetStudentByPK(database.DB, ownerStudentID)
+		if err != nil {
+			return false, err
+		}
+		return actor.LecturerID != nil && owner.AdvisorID != nil && *owner.AdvisorID == *actor.LecturerID, nil
+	}
+	return false, nil
+}
@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"database/sql"
+	"project-uas/app/response"
+	"project-uas/app/service"
+	"project-uas/helper"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // AuthProtected: Validasi Token & Ekstrak Data User
@@ -12,81 +17,87 @@ func AuthProtected(c *fiber.Ctx) error {
 	// 1. Ambil Header Authorization
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Token tidak ditemukan (Unauthorized)",
-		})
+		return response.Unauthorized("Token tidak ditemukan (Unauthorized)")
 	}
 
 	// 2. Format harus "Bearer <token>"
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Format token salah (Gunakan: Bearer <token>)",
-		})
+		return response.Unauthorized("Format token salah (Gunakan: Bearer <token>)")
 	}
 
 	tokenString := parts[1]
 
-	// 3. Parse dan Validasi Token
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.ErrUnauthorized
-		}
-		// PENTING: Secret Key harus SAMA dengan helper/password.go
-		return []byte("RAHASIA_SUPER"), nil 
-	})
-
-	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Token tidak valid atau kadaluwarsa",
-		})
+	// 3. Parse dan Validasi Token (key dipilih berdasarkan header kid)
+	claims, err := helper.Keys().Parse(tokenString)
+	if err != nil {
+		return response.Unauthorized("Token tidak valid atau kadaluwarsa")
 	}
 
-	// 4. Ambil Data Claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "fail",
-			"message": "Gagal membaca data token",
-		})
+	// 4. Cek Denylist (token yang sudah logout / dicabut)
+	jti, _ := claims["jti"].(string)
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if jti == "" || err != nil {
+		return response.Unauthorized("Token tidak valid atau kadaluwarsa")
 	}
 
-	// 5. Simpan data user ke Context (Locals)
-	c.Locals("user_id", claims["user_id"])
-	c.Locals("role_id", claims["role_id"])
-
-	// --- LOGIC TAMBAHAN UNTUK PERMISSION ---
-	// Ambil permissions dari token (biasanya bentuknya []interface{})
-	// Kita perlu konversi jadi []string agar mudah dicek
-	var permissions []string
-	if permsClaim, ok := claims["permissions"].([]interface{}); ok {
-		for _, p := range permsClaim {
-			if str, ok := p.(string); ok {
-				permissions = append(permissions, str)
-			}
-		}
+	iat := helper.TokenIssuedAt(claims)
+	expiresAt, _ := claims.GetExpirationTime()
+	var exp time.Time
+	if expiresAt != nil {
+		exp = expiresAt.Time
 	}
-	// Simpan list permission ke Locals
-	c.Locals("permissions", permissions)
+
+	revoked, err := service.IsAccessTokenRevoked(jti, userID, iat)
+	if err != nil {
+		return response.Internal("Gagal memeriksa status token", err)
+	}
+	if revoked {
+		return response.Unauthorized("Token sudah dicabut (logout)")
+	}
+
+	// 5. Cek status user saat ini (role bisa berubah / user dinonaktifkan setelah token terbit)
+	state, err := service.GetUserAuthState(userID)
+	if err != nil && err != sql.ErrNoRows {
+		return response.Internal("Gagal memeriksa status user", err)
+	}
+	if err == sql.ErrNoRows || !state.IsActive {
+		return response.Unauthorized("User tidak ditemukan atau sudah tidak aktif")
+	}
+
+	// 6. Resolusi aktor (admin / lecturer / student / custom) dari role saat ini
+	actor, err := service.ResolveActor(userID, state.RoleID)
+	if err != nil {
+		return response.Internal("Gagal memeriksa role user", err)
+	}
+
+	// 7. Simpan data user ke Context (Locals)
+	// role_id diambil dari database, bukan dari token
+	c.Locals("user_id", userIDStr)
+	c.Locals("role_id", state.RoleID.String())
+	c.Locals("jti", jti)
+	c.Locals("session_id", claims["sid"])
+	c.Locals("token_exp", exp)
+	c.Locals("actor", actor)
 
 	return c.Next()
 }
 
 // RequirePermission: Middleware Cek Hak Akses (RBAC)
-// Fungsi ini yang tadi error "undefined"
+// Permission di-resolve dari role user saat ini (cache role -> permission),
+// sehingga revoke permission / ganti role langsung berlaku tanpa login ulang.
 func RequirePermission(requiredPerm string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// 1. Ambil list permission user dari Locals (yang diset di AuthProtected)
-		userPerms, ok := c.Locals("permissions").([]string)
-		if !ok {
-			// Jika tidak ada permission sama sekali
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "fail",
-				"message": "Forbidden: No permissions found",
-			})
+		// 1. Ambil permission berdasarkan role_id (diset di AuthProtected)
+		roleIDStr, _ := c.Locals("role_id").(string)
+		roleID, err := uuid.Parse(roleIDStr)
+		if err != nil {
+			return response.Forbidden("Forbidden: No permissions found")
+		}
+		userPerms, err := service.GetRolePermissions(roleID)
+		if err != nil {
+			return response.Internal("Gagal memeriksa permission", err)
 		}
 
 		// 2. Cek apakah user punya permission yang diminta
@@ -100,10 +111,8 @@ func RequirePermission(requiredPerm string) fiber.Handler {
 
 		// 3. Jika tidak punya, tolak request
 		if !hasPermission {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"status":  "fail",
-				"message": "Forbidden: You don't have permission '" + requiredPerm + "'",
-			})
+			return response.Forbidden("Forbidden: You don't have permission '"+requiredPerm+"'").
+				With("required_permission", requiredPerm)
 		}
 
 		// 4. Jika punya, lanjut
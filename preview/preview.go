@@ -0,0 +1,199 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // Registrasi decoder PNG untuk image.Decode
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ContentType: Semua preview disimpan sebagai JPEG
+const ContentType = "image/jpeg"
+
+const (
+	defaultMaxSize   = 320
+	defaultMaxPixels = 40_000_000 // Tolak gambar raksasa (decompression bomb)
+	jpegQuality      = 80
+	pdfRenderTimeout = 30 * time.Second
+)
+
+var (
+	// ErrUnsupported: Tipe file tidak punya generator preview
+	ErrUnsupported = errors.New("tipe file tidak didukung untuk preview")
+	// ErrRendererUnavailable: Renderer PDF (pdftoppm dari poppler-utils) tidak terpasang
+	ErrRendererUnavailable = errors.New("renderer PDF tidak tersedia")
+)
+
+// Generator membuat thumbnail JPEG: gambar JPEG/PNG diperkecil dengan Go murni,
+// halaman pertama PDF dirender dengan pdftoppm (tidak ada renderer PDF Go murni yang memadai).
+type Generator struct {
+	MaxSize     int    // Sisi terpanjang preview (px); gambar yang lebih kecil tidak diperbesar
+	MaxPixels   int    // Batas lebar x tinggi gambar sumber
+	PDFRenderer string // Nama / path executable pdftoppm
+}
+
+// Default: Generator yang dipakai aplikasi (diisi Init saat startup)
+var Default *Generator
+
+// Init membaca ENV:
+//   - PREVIEW_MAX_SIZE     : sisi terpanjang preview dalam px (default 320)
+//   - PREVIEW_PDF_RENDERER : path pdftoppm (default "pdftoppm" dari PATH)
+func Init() error {
+	g := &Generator{MaxSize: defaultMaxSize, MaxPixels: defaultMaxPixels, PDFRenderer: "pdftoppm"}
+	if raw := os.Getenv("PREVIEW_MAX_SIZE"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 16 || n > 2048 {
+			return fmt.Errorf("PREVIEW_MAX_SIZE tidak valid (16-2048): %q", raw)
+		}
+		g.MaxSize = n
+	}
+	if raw := os.Getenv("PREVIEW_PDF_RENDERER"); raw != "" {
+		g.PDFRenderer = raw
+	}
+	Default = g
+	return nil
+}
+
+// FileTypes: Tipe file (hasil sniffing) yang bisa dibuatkan preview
+var FileTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+
+// Supports: contentType termasuk FileTypes
+func Supports(contentType string) bool {
+	for _, t := range FileTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate membuat preview JPEG sesuai tipe file
+func (g *Generator) Generate(ctx context.Context, contentType string, r io.Reader) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg", "image/png":
+		return g.Image(r)
+	case "application/pdf":
+		return g.PDF(ctx, r)
+	}
+	return nil, ErrUnsupported
+}
+
+// Image memperkecil gambar JPEG / PNG menjadi thumbnail JPEG
+func (g *Generator) Image(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > g.MaxPixels {
+		return nil, fmt.Errorf("dimensi gambar %dx%d melebihi batas", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail(src, g.MaxSize), &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF merender halaman pertama PDF menjadi JPEG dengan pdftoppm
+func (g *Generator) PDF(ctx context.Context, r io.Reader) ([]byte, error) {
+	renderer, err := exec.LookPath(g.PDFRenderer)
+	if err != nil {
+		return nil, ErrRendererUnavailable
+	}
+
+	dir, err := os.MkdirTemp("", "preview-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.pdf")
+	f, err := os.Create(input)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pdfRenderTimeout)
+	defer cancel()
+	out := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, renderer,
+		"-jpeg", "-jpegopt", "quality="+strconv.Itoa(jpegQuality),
+		"-f", "1", "-l", "1", "-singlefile",
+		"-scale-to", strconv.Itoa(g.MaxSize),
+		input, out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pdftoppm: %v: %s", err, bytes.TrimSpace(output))
+	}
+	return os.ReadFile(out + ".jpg")
+}
+
+// thumbnail: Perkecil (rata-rata area) agar sisi terpanjang <= maxSize; transparansi diganti latar putih
+func thumbnail(src image.Image, maxSize int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// Ratakan dulu ke RGBA di atas latar putih (JPEG tidak punya alpha)
+	flat := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	dw, dh := sw, sh
+	if sw > maxSize || sh > maxSize {
+		if sw >= sh {
+			dw, dh = maxSize, max(1, sh*maxSize/sw)
+		} else {
+			dw, dh = max(1, sw*maxSize/sh), maxSize
+		}
+	}
+	if dw == sw && dh == sh {
+		return flat
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*sh/dh, max((dy+1)*sh/dh, dy*sh/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*sw/dw, max((dx+1)*sw/dw, dx*sw/dw+1)
+			var r, g, bl, n uint64
+			for y := y0; y < y1; y++ {
+				row := flat.Pix[y*flat.Stride:]
+				for x := x0; x < x1; x++ {
+					r += uint64(row[x*4])
+					g += uint64(row[x*4+1])
+					bl += uint64(row[x*4+2])
+					n++
+				}
+			}
+			i := dy*dst.Stride + dx*4
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(bl/n), 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,77 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerator_Image(t *testing.T) {
+	g := &Generator{MaxSize: 320, MaxPixels: defaultMaxPixels}
+
+	// PNG transparan diperkecil, rasio dipertahankan, latar jadi putih
+	out, err := g.Generate(context.Background(), "image/png", bytes.NewReader(encodePNG(t, 1000, 500, color.Transparent)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("hasil bukan JPEG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 320 || b.Dy() != 160 {
+		t.Errorf("ukuran thumbnail %dx%d, want 320x160", b.Dx(), b.Dy())
+	}
+	if r, _, _, _ := img.At(10, 10).RGBA(); r>>8 < 250 {
+		t.Errorf("latar transparan seharusnya putih, r=%d", r>>8)
+	}
+
+	// Gambar kecil tidak diperbesar
+	out, err = g.Image(bytes.NewReader(encodePNG(t, 40, 100, color.Black)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := jpeg.DecodeConfig(bytes.NewReader(out)); cfg.Width != 40 || cfg.Height != 100 {
+		t.Errorf("gambar kecil berubah ukuran: %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestGenerator_RejectsHugeAndInvalid(t *testing.T) {
+	g := &Generator{MaxSize: 320, MaxPixels: 100 * 100}
+	if _, err := g.Image(bytes.NewReader(encodePNG(t, 200, 200, color.Black))); err == nil {
+		t.Error("gambar di atas MaxPixels seharusnya ditolak")
+	}
+	if _, err := g.Image(strings.NewReader("bukan gambar")); err == nil {
+		t.Error("data rusak seharusnya error")
+	}
+	if _, err := g.Generate(context.Background(), "image/gif", strings.NewReader("GIF89a")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("gif: err=%v, want ErrUnsupported", err)
+	}
+}
+
+func TestGenerator_PDFWithoutRenderer(t *testing.T) {
+	g := &Generator{MaxSize: 320, PDFRenderer: "pdftoppm-tidak-ada"}
+	_, err := g.Generate(context.Background(), "application/pdf", strings.NewReader("%PDF-1.4"))
+	if !errors.Is(err, ErrRendererUnavailable) {
+		t.Errorf("err=%v, want ErrRendererUnavailable", err)
+	}
+}
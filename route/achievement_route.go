@@ -1,6 +1,7 @@
 package route
 
 import (
+	"project-uas/app/model"
 	"project-uas/middleware"
 	"project-uas/app/service"
 
@@ -13,21 +14,47 @@ func SetupAchievementRoutes(api fiber.Router) {
 	// Middleware Auth Wajib
 	achievements.Use(middleware.AuthProtected)
 
+	// Policy per prestasi (pemilik / dosen wali / admin)
+	canView := middleware.RequireAchievementAccess(middleware.AchievementView)
+	canEdit := middleware.RequireAchievementAccess(middleware.AchievementEdit)
+	canVerify := middleware.RequireAchievementAccess(middleware.AchievementVerify)
+
+	// Trash (Admin): harus sebelum /:id
+	trash := achievements.Group("/trash", middleware.RequirePermission(model.PermissionAchievementTrash))
+	trash.Get("/", service.ListTrashedAchievements)
+	trash.Post("/:id/restore", service.RestoreAchievement)
+	trash.Delete("/:id", service.PurgeAchievement)
+
 	// List & Detail
 	achievements.Get("/", service.ListAchievements)
-	achievements.Get("/:id", service.GetAchievementDetail)
+	achievements.Get("/search", service.SearchAchievements) // Harus sebelum /:id
+	achievements.Get("/:id", canView, service.GetAchievementDetail)
 
 	// Mahasiswa Actions
-	achievements.Post("/", service.CreateAchievement)           // Create Draft
-	achievements.Put("/:id", service.UpdateAchievement)         // Update Draft
-	achievements.Delete("/:id", service.DeleteAchievement)      // Delete Draft
-	achievements.Post("/:id/submit", service.SubmitAchievement) // Submit to Dosen
-	achievements.Post("/:id/attachments", service.UploadAttachment) // Upload File
+	achievements.Post("/", service.CreateAchievement)                        // Create Draft
+	achievements.Put("/:id", canEdit, service.UpdateAchievement)             // Update Draft / Revisi
+	achievements.Delete("/:id", canEdit, service.DeleteAchievement)          // Delete Draft
+	achievements.Post("/:id/submit", canEdit, service.SubmitAchievement)     // Submit / Resubmit to Dosen
+	achievements.Post("/:id/attachments", canEdit, service.UploadAttachment) // Upload File
+
+	// Lampiran: stream terautentikasi atau signed URL sementara
+	achievements.Get("/:id/attachments/:attachmentId", canView, service.DownloadAttachment)
+	achievements.Get("/:id/attachments/:attachmentId/url", canView, service.GetAttachmentURL)
+	achievements.Put("/:id/attachments/:attachmentId", canEdit, service.ReplaceAttachment)    // Ganti File
+	achievements.Delete("/:id/attachments/:attachmentId", canEdit, service.DeleteAttachment) // Hapus File
 
 	// Dosen Actions
-	achievements.Post("/:id/verify", service.VerifyAchievement) // Verify
-	achievements.Post("/:id/reject", service.RejectAchievement) // Reject
+	achievements.Post("/:id/verify", canVerify, service.VerifyAchievement) // Verify
+	achievements.Post("/:id/reject", canVerify, service.RejectAchievement) // Reject
+	achievements.Post("/:id/request-changes", canVerify, service.RequestAchievementChanges) // Minta perbaikan
 
 	// History
-	achievements.Get("/:id/history", service.GetAchievementHistory)
-}
\ No newline at end of file
+	achievements.Get("/:id/history", canView, service.GetAchievementHistory)
+	achievements.Get("/:id/revisions", canView, service.GetAchievementRevisions)
+	achievements.Get("/:id/revisions/diff", canView, service.DiffAchievementRevisions)
+
+	// Versi konten (setiap perubahan)
+	achievements.Get("/:id/versions", canView, service.ListAchievementVersions)
+	achievements.Get("/:id/versions/diff", canView, service.DiffAchievementVersions)
+	achievements.Get("/:id/versions/:version", canView, service.GetAchievementVersion)
+}
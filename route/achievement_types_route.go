@@ -0,0 +1,24 @@
+package route
+
+import (
+	"project-uas/app/model"
+	"project-uas/app/service"
+	"project-uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAchievementTypeRoutes(api fiber.Router) {
+	types := api.Group("/achievement-types")
+	types.Use(middleware.AuthProtected)
+
+	// Semua user login bisa membaca (form prestasi di frontend)
+	types.Get("/", service.GetAllAchievementTypes)
+	types.Get("/:code", service.GetAchievementType)
+
+	// Kelola registry: hanya user dengan permission achievement_type:manage
+	manage := middleware.RequirePermission(model.PermissionAchievementTypeManage)
+	types.Post("/", manage, service.CreateAchievementType)
+	types.Put("/:code", manage, service.UpdateAchievementType)
+	types.Delete("/:code", manage, service.DeleteAchievementType)
+}
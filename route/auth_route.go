@@ -17,5 +17,6 @@ func SetupAuthRoutes(api fiber.Router) {
 	// Endpoint Protected (Butuh Token)
 	auth.Use(middleware.AuthProtected)
 	auth.Post("/logout", service.Logout)
+	auth.Post("/logout-all", service.LogoutAll)
 	auth.Get("/profile", service.GetProfile)
 }
\ No newline at end of file
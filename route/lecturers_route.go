@@ -17,7 +17,7 @@ func SetupLecturerRoutes(api fiber.Router) {
 	lecturers.Post("/", middleware.RequirePermission("lecturer:create"), service.CreateLecturer)
 	lecturers.Get("/", service.GetAllLecturers)
 	lecturers.Get("/:id", service.GetLecturerByUserID)
-	lecturers.Put("/:id", service.UpdateLecturer)
+	lecturers.Put("/:id", middleware.RequirePermission("lecturer:update"), service.UpdateLecturer)
 	
 	// Endpoint Khusus SRS (FR-006)
 	lecturers.Get("/:id/advisees", service.GetLecturerAdvisees)
@@ -1,7 +1,9 @@
 package route
 
 import (
+	"project-uas/app/model"
 	"project-uas/app/service"
+	"project-uas/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,9 +11,13 @@ import (
 func SetupPermissionRoutes(api fiber.Router) {
 	permissions := api.Group("/permissions")
 
+	// Hanya user dengan permission permission:manage
+	permissions.Use(middleware.AuthProtected)
+	permissions.Use(middleware.RequirePermission(model.PermissionPermissionManage))
+
 	permissions.Get("/", service.GetAllPermissions)
 	permissions.Get("/:id", service.GetPermissionByID)
 	permissions.Post("/", service.CreatePermission)
 	permissions.Put("/:id", service.UpdatePermission)
 	permissions.Delete("/:id", service.DeletePermission)
-}
\ No newline at end of file
+}
@@ -1,7 +1,9 @@
 package route
 
 import (
+	"project-uas/app/model"
 	"project-uas/app/service"
+	"project-uas/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,10 +11,14 @@ import (
 func SetupRolePermissionRoutes(api fiber.Router) {
 	rp := api.Group("/role-permissions")
 
+	// Hanya user dengan permission role:manage
+	rp.Use(middleware.AuthProtected)
+	rp.Use(middleware.RequirePermission(model.PermissionRoleManage))
+
 	// Mendapat semua permission yang dimiliki role
 	rp.Get("/:role_id", service.GetPermissionsByRoleID)
 	// Memberi permission ke role
 	rp.Post("/", service.AssignPermissionToRole)
 	// Menghapus permission dari role
 	rp.Delete("/:role_id/:permission_id", service.RevokePermissionFromRole)
-}
\ No newline at end of file
+}
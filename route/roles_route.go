@@ -1,7 +1,9 @@
 package route
 
 import (
+	"project-uas/app/model"
 	"project-uas/app/service"
+	"project-uas/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,9 +11,13 @@ import (
 func SetupRoleRoutes(api fiber.Router) {
 	roles := api.Group("/roles")
 
+	// Hanya user dengan permission role:manage
+	roles.Use(middleware.AuthProtected)
+	roles.Use(middleware.RequirePermission(model.PermissionRoleManage))
+
 	roles.Get("/", service.GetAllRoles)
 	roles.Get("/:id", service.GetRoleByID)
 	roles.Post("/", service.CreateRole)
 	roles.Put("/:id", service.UpdateRole)
 	roles.Delete("/:id", service.DeleteRole)
-}
\ No newline at end of file
+}
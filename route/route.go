@@ -1,8 +1,15 @@
 package route
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"project-uas/app/service"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 func SetupRoutes(app *fiber.App) {
+	// Public key untuk verifikasi token oleh service lain
+	app.Get("/.well-known/jwks.json", service.GetJWKS)
+
 	api := app.Group("/api/v1") 
 
 
@@ -13,7 +20,12 @@ func SetupRoutes(app *fiber.App) {
 	SetupUserRoutes(api)
 	SetupStudentRoutes(api)
 	SetupLecturerRoutes(api)
+	SetupAchievementTypeRoutes(api)
+	SetupScoringRuleRoutes(api)
 	SetupAchievementRoutes(api)
 	SetupReportRoutes(api)
 
+	// Signed URL lampiran (driver storage local), tanpa auth: dicek lewat signature
+	api.Get("/files/*", service.ServeSignedFile)
+
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"project-uas/app/model"
+	"project-uas/app/service"
+	"project-uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupScoringRuleRoutes(api fiber.Router) {
+	rules := api.Group("/scoring-rules")
+
+	// Hanya user dengan permission scoring_rule:manage
+	rules.Use(middleware.AuthProtected)
+	rules.Use(middleware.RequirePermission(model.PermissionScoringRuleManage))
+
+	rules.Get("/", service.GetAllScoringRules)
+	rules.Post("/", service.CreateScoringRule)
+	rules.Put("/:id", service.UpdateScoringRule)
+	rules.Delete("/:id", service.DeleteScoringRule)
+}
@@ -17,9 +17,10 @@ func SetupStudentRoutes(api fiber.Router) {
 	students.Post("/", middleware.RequirePermission("student:create"), service.CreateStudent)
 	students.Get("/", service.GetAllStudents)
 	students.Get("/:id", service.GetStudentByUserID)
-	students.Put("/:id", service.UpdateStudent)
+	students.Put("/:id", middleware.RequirePermission("student:update"), service.UpdateStudent)
 	
 	// Endpoint Khusus SRS
 	students.Get("/:id/achievements", service.GetStudentAchievements)
-	students.Put("/:id/advisor", service.UpdateStudentAdvisor)
+	// Dosen wali menentukan akses dosen ke prestasi mahasiswa, jadi hanya admin (student:update)
+	students.Put("/:id/advisor", middleware.RequirePermission("student:update"), service.UpdateStudentAdvisor)
 }
\ No newline at end of file
@@ -32,4 +32,7 @@ func SetupUserRoutes(api fiber.Router) {
 
 	// PUT /api/v1/users/:id/role (Butuh: user:assign_role)
 	users.Put("/:id/role", middleware.RequirePermission("user:assign_role"), service.UpdateUserRole)
+
+	// POST /api/v1/users/:id/logout-all (Butuh: user:update)
+	users.Post("/:id/logout-all", middleware.RequirePermission("user:update"), service.RevokeUserSessions)
 }
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Ukuran chunk INSTREAM; harus di bawah StreamMaxLength clamd (default 25MB)
+const clamChunkSize = 64 << 10
+
+// ClamAV: Scanner yang mengirim file ke daemon clamd lewat perintah INSTREAM (TCP atau unix socket)
+type ClamAV struct {
+	network string // "tcp" / "unix"
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV menerima alamat "tcp://host:port", "unix:///path/clamd.sock", atau "host:port"
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	if address == "" {
+		return nil, errors.New("alamat clamd wajib diisi")
+	}
+	return &ClamAV{network: network, address: address, timeout: timeout}, nil
+}
+
+func (s *ClamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd tidak bisa dihubungi: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Format INSTREAM: "zINSTREAM\0", lalu chunk <panjang uint32 big-endian><data>, diakhiri panjang 0
+	w := bufio.NewWriterSize(conn, clamChunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, clamChunkSize)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if err := binary.Write(w, binary.BigEndian, uint32(n)); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(0)); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return nil, fmt.Errorf("gagal membaca balasan clamd: %w", err)
+	}
+	return parseClamReply(reply)
+}
+
+// parseClamReply: "stream: OK", "stream: Eicar-Signature FOUND", atau "... ERROR"
+func parseClamReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	status := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case status == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("clamd: %s", reply)
+}
@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// EICAR: File uji standar yang dikenali semua antivirus sebagai malware (tidak berbahaya)
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// Fake: Scanner untuk test; file dianggap terinfeksi jika memuat salah satu pola di Signatures.
+// Err diisi untuk mensimulasikan scanner yang tidak tersedia.
+type Fake struct {
+	Signatures map[string][]byte // nama signature -> pola byte
+	Err        error
+}
+
+// NewFake: Fake yang mengenali file uji EICAR
+func NewFake() *Fake {
+	return &Fake{Signatures: map[string][]byte{"Eicar-Test-Signature": []byte(EICAR)}}
+}
+
+func (s *Fake) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	for name, pattern := range s.Signatures {
+		if bytes.Contains(data, pattern) {
+			return &Result{Infected: true, Signature: name}, nil
+		}
+	}
+	return &Result{}, nil
+}
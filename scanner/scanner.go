@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Result: Hasil pemindaian satu file
+type Result struct {
+	Infected  bool
+	Signature string // Nama malware dari scanner, kosong jika bersih
+}
+
+// Scanner: Pemindai malware untuk file lampiran. Error berarti file belum bisa dinilai
+// (scanner mati / timeout), bukan berarti file bersih.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// Default: Scanner yang dipakai aplikasi (diisi Init saat startup); nil = pemindaian dinonaktifkan
+var Default Scanner
+
+// Init memilih driver dari ENV:
+//   - SCANNER_DRIVER=clamav (default): CLAMAV_ADDRESS (default tcp://127.0.0.1:3310, atau
+//     unix:///var/run/clamav/clamd.ctl), CLAMAV_TIMEOUT (default 30s)
+//   - SCANNER_DRIVER=none: tanpa pemindaian (hanya untuk development)
+func Init() error {
+	switch driver := os.Getenv("SCANNER_DRIVER"); driver {
+	case "", "clamav":
+		timeout := 30 * time.Second
+		if raw := os.Getenv("CLAMAV_TIMEOUT"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("CLAMAV_TIMEOUT tidak valid: %q", raw)
+			}
+			timeout = d
+		}
+		address := os.Getenv("CLAMAV_ADDRESS")
+		if address == "" {
+			address = "tcp://127.0.0.1:3310"
+		}
+		clam, err := NewClamAV(address, timeout)
+		if err != nil {
+			return err
+		}
+		Default = clam
+	case "none":
+		log.Println("PERINGATAN: pemindaian malware lampiran dinonaktifkan (SCANNER_DRIVER=none)")
+		Default = nil
+	default:
+		return fmt.Errorf("SCANNER_DRIVER tidak dikenal: %s", driver)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd: Server clamd minimal yang memahami INSTREAM dan mendeteksi EICAR
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				cmd := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, cmd); err != nil || string(cmd) != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, conn, int64(size)); err != nil {
+						return
+					}
+				}
+				if bytes.Contains(data.Bytes(), []byte(EICAR)) {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	clam, err := NewClamAV(fakeClamd(t), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lebih besar dari satu chunk agar framing INSTREAM ikut teruji
+	clean := bytes.Repeat([]byte("%PDF-1.4 bersih "), 10000)
+	res, err := clam.Scan(context.Background(), bytes.NewReader(clean))
+	if err != nil || res.Infected {
+		t.Fatalf("file bersih: res=%+v err=%v", res, err)
+	}
+
+	infected := append(append([]byte{}, clean...), EICAR...)
+	res, err = clam.Scan(context.Background(), bytes.NewReader(infected))
+	if err != nil || !res.Infected || res.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("file EICAR: res=%+v err=%v", res, err)
+	}
+}
+
+func TestClamAV_Unavailable(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	clam, _ := NewClamAV(addr, time.Second)
+	if _, err := clam.Scan(context.Background(), strings.NewReader("x")); err == nil {
+		t.Fatal("scanner mati harus menghasilkan error, bukan hasil bersih")
+	}
+}
+
+func TestParseClamReply(t *testing.T) {
+	if _, err := parseClamReply("INSTREAM size limit exceeded. ERROR\x00"); err == nil {
+		t.Error("balasan ERROR harus menjadi error")
+	}
+	if res, err := parseClamReply("stream: Win.Test.EICAR_HDB-1 FOUND\x00"); err != nil || res.Signature != "Win.Test.EICAR_HDB-1" {
+		t.Errorf("res=%+v err=%v", res, err)
+	}
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake()
+	if res, _ := fake.Scan(context.Background(), strings.NewReader("halo")); res.Infected {
+		t.Error("file biasa dianggap terinfeksi")
+	}
+	if res, _ := fake.Scan(context.Background(), strings.NewReader("xx"+EICAR)); !res.Infected {
+		t.Error("EICAR tidak terdeteksi")
+	}
+	fake.Err = errors.New("down")
+	if _, err := fake.Scan(context.Background(), strings.NewReader("halo")); err == nil {
+		t.Error("Err harus dikembalikan")
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound: Objek dengan key tersebut tidak ada di storage
+var ErrNotFound = errors.New("objek storage tidak ditemukan")
+
+// ObjectInfo: Metadata objek yang dibaca dari storage
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// Storage: Backend penyimpanan file lampiran. Key bersifat content-addressed (lihat ContentKey),
+// jadi Put dengan key yang sama aman diulang dan tidak pernah menimpa isi yang berbeda.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL: URL unduh sementara yang bisa dipakai tanpa header Authorization
+	SignedURL(ctx context.Context, key string, opts SignedURLOptions) (string, error)
+}
+
+// SignedURLOptions: Masa berlaku + nama file & content type yang dikirim saat URL diakses
+type SignedURLOptions struct {
+	Expires     time.Duration
+	FileName    string // Kosong = tanpa Content-Disposition
+	ContentType string
+}
+
+// Default: Storage yang dipakai aplikasi (diisi Init saat startup)
+var Default Storage
+
+// Init memilih driver dari ENV:
+//   - STORAGE_DRIVER=local (default): STORAGE_LOCAL_DIR (default ./uploads), STORAGE_SIGNING_KEY,
+//     STORAGE_PUBLIC_URL (default /api/v1/files)
+//   - STORAGE_DRIVER=s3: S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY,
+//     S3_PATH_STYLE (default true, untuk MinIO / penyedia S3-compatible)
+func Init() error {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		signingKey := []byte(os.Getenv("STORAGE_SIGNING_KEY"))
+		if len(signingKey) == 0 {
+			// Tanpa key tetap jalan, tapi signed URL tidak berlaku lintas restart / instance
+			signingKey = make([]byte, 32)
+			if _, err := rand.Read(signingKey); err != nil {
+				return err
+			}
+		}
+		local, err := NewLocal(getenv("STORAGE_LOCAL_DIR", "./uploads"), signingKey, getenv("STORAGE_PUBLIC_URL", "/api/v1/files"))
+		if err != nil {
+			return err
+		}
+		Default = local
+	case "s3":
+		s3, err := NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    getenv("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: getenv("S3_PATH_STYLE", "true") == "true",
+		})
+		if err != nil {
+			return err
+		}
+		Default = s3
+	default:
+		return fmt.Errorf("STORAGE_DRIVER tidak dikenal: %s", driver)
+	}
+	return nil
+}
+
+// ContentKey menghitung key content-addressed dari isi file: attachments/ab/abcdef... (sha256 hex).
+// Mengembalikan juga hash hex-nya. Reader dibaca sampai habis.
+func ContentKey(r io.Reader) (key string, sum string, err error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", "", err
+	}
+	sum = hex.EncodeToString(h.Sum(nil))
+	return "attachments/" + sum[:2] + "/" + sum, sum, nil
+}
+
+// QuarantineKey: Lokasi file yang terdeteksi malware, terpisah dari key lampiran biasa
+// sehingga tidak pernah ikut terbaca lewat key content-addressed yang sama
+func QuarantineKey(key string) string {
+	return "quarantine/" + key
+}
+
+// PreviewKey: Lokasi thumbnail / preview halaman pertama, di samping file aslinya
+func PreviewKey(key string) string {
+	return key + ".preview.jpg"
+}
+
+// validKey menolak key kosong, absolut, atau yang keluar dari root (../)
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}